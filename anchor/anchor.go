@@ -0,0 +1,73 @@
+package anchor
+
+import (
+	"fmt"
+
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/mmr"
+)
+
+// Prefix of the data of blocks which anchor a document.
+const Prefix = "anchor:"
+
+// Proof a document was anchored in a chain.
+// Third parties can verify it offline against a root they trust, such as one published by the chain's operators.
+type Proof struct {
+	Doc    []byte     `json:"doc"`    // Hash of the document.
+	Header []byte     `json:"header"` // Header of the block the document is anchored in.
+	Path   *mmr.Proof `json:"path"`   // Proof the block is in the chain.
+}
+
+// Gets the data for a block which anchors the document with the hash.
+func Data(doc []byte) string {
+	return Prefix + hex.EncodeToString(doc)
+}
+
+// Creates a block anchoring the document with the hash, following the previous block.
+// If the previous block does not hold a chunk, error is returned.
+func New(blk *miner.Block, dif int, doc []byte) (*miner.Block, error) {
+	return miner.NewWith(blk, miner.NewChunk, dif, Data(doc))
+}
+
+// Proves the document with the hash is anchored in the chain.
+// Returns the root of the chain the proof verifies against.
+// If no block anchors the document, error is returned.
+func Prove(c *chain.Chain, doc []byte) (root []byte, p *Proof, err error) {
+	d := Data(doc)
+	for _, blk := range c.Blocks {
+		ck, ok := blk.Miner.(*miner.Chunk)
+		if !ok || ck.Data != d {
+			continue
+		}
+
+		root, path, err := c.ProveAncestor(ck.GetHash())
+		if err != nil {
+			return nil, nil, fmt.Errorf("can not prove anchor, %w", err)
+		}
+
+		return root, &Proof{Doc: doc, Header: ck.EncodeHeader(), Path: path}, nil
+	}
+
+	return nil, nil, fmt.Errorf("can not prove anchor, %w", chainerr.ErrNotFound)
+}
+
+// Verifies the proof shows the document is anchored in the chain with the root.
+func Verify(root []byte, p *Proof) bool {
+	if p == nil {
+		return false
+	}
+
+	ck, err := miner.DecodeHeader(p.Header)
+	if err != nil || ck.Data != Data(p.Doc) {
+		return false
+	}
+
+	h := sha256.Sum256(p.Header)
+
+	return mmr.Verify(root, h[:], p.Path)
+}
@@ -0,0 +1,88 @@
+package anchor
+
+import (
+	"errors"
+	"testing"
+
+	"crypto/sha256"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test an anchored document can be proven and verified.
+func TestProve(t *testing.T) {
+	doc := sha256.Sum256([]byte("Hello Document"))
+	c := createChain(doc[:])
+
+	root, p, err := Prove(c, doc[:])
+	if err != nil {
+		t.Fatalf("expected proof but got %s", err)
+	}
+
+	if !Verify(root, p) {
+		t.Errorf("expected proof to verify")
+	}
+
+	// Proof does not hold for another document, or another root.
+	other := sha256.Sum256([]byte("Other Document"))
+	p2 := *p
+	p2.Doc = other[:]
+	if Verify(root, &p2) {
+		t.Errorf("expected proof to fail for another document")
+	}
+
+	if Verify(c.Blocks[0].GetHash(), p) || Verify(root, nil) {
+		t.Errorf("expected proof to fail for another root")
+	}
+
+	// Tampered header.
+	p2 = *p
+	p2.Header = append([]byte(nil), p.Header...)
+	p2.Header[40] ^= 1
+	if Verify(root, &p2) {
+		t.Errorf("expected proof to fail for a tampered header")
+	}
+
+	if _, _, err := Prove(c, other[:]); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
+// Test anchoring after a block which does not hold a chunk returns an error instead of panicking.
+func TestNewNotChunk(t *testing.T) {
+	doc := sha256.Sum256([]byte("Hello Document"))
+	prev := &miner.Block{Miner: customMiner{Chunk: &miner.Chunk{}}}
+
+	if _, err := New(prev, 1, doc[:]); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+}
+
+// Miner which is not a chunk.
+type customMiner struct {
+	*miner.Chunk
+}
+
+// Creates a chain with the document anchored in the middle.
+func createChain(doc []byte) *chain.Chain {
+	c := chain.New()
+
+	blk := miner.New(nil, 1, "Block")
+	for i := 0; i < 4; i++ {
+		if i > 0 {
+			if i == 2 {
+				blk, _ = New(blk, 1, doc)
+			} else {
+				blk = miner.New(blk, 1, "Block")
+			}
+		}
+
+		blk.Mine()
+		blk.GenerateHash(true)
+		c.Append(true, blk)
+	}
+
+	return c
+}
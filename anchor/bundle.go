@@ -0,0 +1,124 @@
+package anchor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Portable proof that a document existed before a block, like an OpenTimestamps file.
+// It holds the header chain from genesis to the block, so it can be verified with only
+// the genesis hash and chain spec, without trusting a root or a node.
+type Bundle struct {
+	Doc     []byte   `json:"doc"`     // Hash of the document.
+	Anchor  int      `json:"anchor"`  // Index of the header anchoring the document.
+	Headers [][]byte `json:"headers"` // Headers from genesis to the last block, in order.
+}
+
+// Exports a bundle proving the document with the hash was anchored before the head of the chain.
+// If no block anchors the document, error is returned.
+func Export(c *chain.Chain, doc []byte) (*Bundle, error) {
+	b := &Bundle{Doc: doc, Anchor: -1}
+	d := Data(doc)
+
+	for _, blk := range c.Blocks {
+		ck, ok := blk.Miner.(*miner.Chunk)
+		if !ok {
+			return nil, fmt.Errorf("can not export bundle, %w", chainerr.ErrInvalidMiner)
+		}
+
+		if b.Anchor == -1 && ck.Data == d {
+			b.Anchor = len(b.Headers)
+		}
+		b.Headers = append(b.Headers, ck.EncodeHeader())
+	}
+
+	if b.Anchor == -1 {
+		return nil, fmt.Errorf("can not export bundle, %w", chainerr.ErrNotFound)
+	}
+
+	return b, nil
+}
+
+// Encodes the bundle to JSON format, for saving to a file.
+func (b Bundle) Encode() (j []byte) {
+	j, _ = json.Marshal(b)
+
+	return
+}
+
+// Decodes a bundle from JSON format.
+func DecodeBundle(j []byte) (*Bundle, error) {
+	b := new(Bundle)
+	if err := json.Unmarshal(j, b); err != nil {
+		return nil, fmt.Errorf("can not decode bundle, %w", err)
+	}
+
+	return b, nil
+}
+
+// Verifies the bundle against the genesis hash and chain ID, and the spec's difficulties and intervals if one is given.
+// Every header must follow the one before it with a valid PoW, starting from genesis.
+// Without a spec, headers of any difficulty and interval are accepted, so the spec should be given where the work matters.
+// Returns the timestamp of the anchoring block, the time the document is proven to have existed by.
+func (b Bundle) Verify(genesis []byte, id int, spec chain.Spec) (time.Time, error) {
+	if len(b.Headers) == 0 || b.Anchor < 0 || b.Anchor >= len(b.Headers) {
+		return time.Time{}, errors.New("bundle has no anchor")
+	}
+
+	var prev *miner.Chunk
+	var anchor *miner.Chunk
+	for i, h := range b.Headers {
+		ck, err := miner.DecodeHeader(h)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if i == 0 {
+			if !ck.IsGenesis() || !bytes.Equal(ck.Hash, genesis) {
+				return time.Time{}, fmt.Errorf("genesis %w", chainerr.ErrInvalidHash)
+			}
+		} else {
+			if !bytes.Equal(ck.GetParentHash(), prev.Hash) {
+				return time.Time{}, fmt.Errorf("header %d %w", i, chainerr.ErrOrphanBlock)
+			}
+			ck.Parent = prev
+		}
+
+		if err := ck.ValidateHeader(); err != nil {
+			return time.Time{}, fmt.Errorf("header %d, %w", i, err)
+		}
+
+		if ck.ChainID != id {
+			return time.Time{}, fmt.Errorf("header %d %w", i, chainerr.ErrWrongChain)
+		}
+
+		if spec != nil {
+			if ck.Difficulty != spec.DifficultyAt(ck.Index) {
+				return time.Time{}, fmt.Errorf("header %d %w", i, chainerr.ErrBadDifficulty)
+			}
+
+			if i > 0 && ck.GetTimestamp().Sub(prev.GetTimestamp()) < spec.MinIntervalAt(ck.Index) {
+				return time.Time{}, fmt.Errorf("header %d %w", i, chainerr.ErrBadTimestamp)
+			}
+		}
+
+		if i == b.Anchor {
+			anchor = ck
+		}
+		prev = ck
+	}
+
+	if anchor.Data != Data(b.Doc) {
+		return time.Time{}, errors.New("anchor does not hold the document")
+	}
+
+	return anchor.GetTimestamp(), nil
+}
@@ -0,0 +1,105 @@
+package anchor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"crypto/sha256"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/network"
+)
+
+// Test a bundle can be exported, imported, and verified with only the genesis hash.
+func TestBundle(t *testing.T) {
+	doc := sha256.Sum256([]byte("Hello Document"))
+	c := createChain(doc[:])
+	genesis := c.Blocks[0].GetHash()
+
+	b, err := Export(c, doc[:])
+	if err != nil {
+		t.Fatalf("expected bundle but got %s", err)
+	}
+
+	b, err = DecodeBundle(b.Encode())
+	if err != nil {
+		t.Fatalf("expected bundle to decode but got %s", err)
+	}
+
+	if b.Anchor != 2 || len(b.Headers) != 4 {
+		t.Errorf("expected anchor at 2 of 4 headers but got %d of %d", b.Anchor, len(b.Headers))
+	}
+
+	ts, err := b.Verify(genesis, 0, nil)
+	if err != nil {
+		t.Fatalf("expected bundle to verify but got %s", err)
+	}
+
+	if e := c.Blocks[2].GetTimestamp(); !ts.Equal(e) {
+		t.Errorf("expected anchor timestamp of %s but got %s", e, ts)
+	}
+
+	// Another network has another genesis.
+	if _, err := b.Verify(sha256.New().Sum(nil), 0, nil); !errors.Is(err, chainerr.ErrInvalidHash) {
+		t.Errorf("expected invalid hash error but got %v", err)
+	}
+
+	// Spec the chain follows.
+	if _, err := b.Verify(genesis, 0, network.Network{Difficulty: 1}); err != nil {
+		t.Errorf("expected bundle to verify against the spec but got %s", err)
+	}
+
+	// Spec with another difficulty.
+	if _, err := b.Verify(genesis, 0, network.Mainnet); !errors.Is(err, chainerr.ErrBadDifficulty) {
+		t.Errorf("expected bad difficulty error but got %v", err)
+	}
+
+	// Spec with a longer interval than the chain's blocks.
+	if _, err := b.Verify(genesis, 0, network.Network{Difficulty: 1, MinInterval: time.Hour}); !errors.Is(err, chainerr.ErrBadTimestamp) {
+		t.Errorf("expected bad timestamp error but got %v", err)
+	}
+
+	// Another chain ID.
+	if _, err := b.Verify(genesis, network.Mainnet.ID, nil); !errors.Is(err, chainerr.ErrWrongChain) {
+		t.Errorf("expected wrong chain error but got %v", err)
+	}
+}
+
+// Test tampered bundles fail to verify.
+func TestBundleTampered(t *testing.T) {
+	doc := sha256.Sum256([]byte("Hello Document"))
+	c := createChain(doc[:])
+	genesis := c.Blocks[0].GetHash()
+	b, _ := Export(c, doc[:])
+
+	// Header left out.
+	tb := *b
+	tb.Headers = append(tb.Headers[:1:1], tb.Headers[2:]...)
+	if _, err := tb.Verify(genesis, 0, nil); err == nil {
+		t.Errorf("expected bundle with a missing header to fail")
+	}
+
+	// Another document.
+	tb = *b
+	other := sha256.Sum256([]byte("Other Document"))
+	tb.Doc = other[:]
+	if _, err := tb.Verify(genesis, 0, nil); err == nil {
+		t.Errorf("expected bundle for another document to fail")
+	}
+
+	// Anchor moved to an unanchored header.
+	tb = *b
+	tb.Anchor = 1
+	if _, err := tb.Verify(genesis, 0, nil); err == nil {
+		t.Errorf("expected bundle with a moved anchor to fail")
+	}
+
+	if _, err := Export(c, other[:]); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+
+	if _, err := DecodeBundle([]byte("{")); err == nil {
+		t.Errorf("expected malformed bundle to fail")
+	}
+}
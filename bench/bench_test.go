@@ -0,0 +1,157 @@
+package bench
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chaingen"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Blocks in the generated corpus.
+const corpusBlocks = 64
+
+// Generates the corpus of blocks to benchmark against.
+func corpus(b *testing.B) *chaingen.Result {
+	b.Helper()
+
+	r, err := chaingen.Generate(chaingen.Config{Seed: 1, Blocks: corpusBlocks, MaxTransfers: 16, Upgrades: 2})
+	if err != nil {
+		b.Fatalf("expected corpus to generate but got %s", err)
+	}
+
+	return r
+}
+
+// Gets the chunk of the corpus's last block.
+func lastChunk(b *testing.B) *miner.Chunk {
+	blk, _ := corpus(b).Chain.Last()
+
+	return blk.Miner.(*miner.Chunk)
+}
+
+// Balances of accounts, applying the transfers of the corpus.
+type balances map[string]int
+
+// Applies the transfers of a block's data.
+func (bs balances) apply(data string) error {
+	var ts []chaingen.Transfer
+	if err := json.Unmarshal([]byte(data), &ts); err != nil {
+		return err
+	}
+
+	for _, t := range ts {
+		bs[t.From] -= t.Amount
+		bs[t.To] += t.Amount
+	}
+
+	return nil
+}
+
+// Hashes the balances as the state root.
+func (bs balances) commit() []byte {
+	j, _ := json.Marshal(bs)
+	sum := sha256.Sum256(j)
+
+	return sum[:]
+}
+
+// Benchmark hashing a chunk's header.
+func BenchmarkGenerateHash(b *testing.B) {
+	ck := lastChunk(b)
+	b.SetBytes(int64(len(ck.EncodeHeader())))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ck.GenerateHash(false)
+	}
+}
+
+// Benchmark a single PoW attempt, the inner loop of mining.
+func BenchmarkValidatePoW(b *testing.B) {
+	ck := lastChunk(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ck.ValidatePoW(uint64(i))
+	}
+}
+
+// Benchmark mining a chunk at a difficulty of 2.
+func BenchmarkMine(b *testing.B) {
+	ck := lastChunk(b)
+	ck.Difficulty = 2
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ck.PoW, ck.ExtraNonce = 0, uint64(i)
+		ck.Mine()
+	}
+}
+
+// Benchmark validating a block's header and body.
+func BenchmarkValidate(b *testing.B) {
+	blk, _ := corpus(b).Chain.Last()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := chain.Validate(blk); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark applying the transfers of every block in the corpus and committing the state.
+func BenchmarkApplyState(b *testing.B) {
+	blks := corpus(b).Chain.Blocks
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bs := make(balances)
+		for _, blk := range blks {
+			if err := bs.apply(blk.Miner.(*miner.Chunk).Data); err != nil {
+				b.Fatal(err)
+			}
+			bs.commit()
+		}
+	}
+}
+
+// Benchmark appending every block in the corpus to a new chain, with validation.
+func BenchmarkAppend(b *testing.B) {
+	r := corpus(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c := r.Network.Chain()
+		for _, blk := range r.Chain.Blocks {
+			if err := c.Append(true, blk); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// Benchmark storing every block in the corpus as a side chain, with validation.
+func BenchmarkStoreBlock(b *testing.B) {
+	r := corpus(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c := r.Network.Chain()
+		for _, blk := range r.Chain.Blocks {
+			if err := c.StoreBlock(true, blk); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
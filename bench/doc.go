@@ -0,0 +1,6 @@
+// Package bench holds benchmarks for hashing, mining, validation, state, and storage.
+// Track results across changes with benchstat so regressions are visible:
+//
+//	go test -run ^$ -bench . -benchmem -count 10 ./bench > new.txt
+//	benchstat old.txt new.txt
+package bench
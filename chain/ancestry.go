@@ -0,0 +1,130 @@
+package chain
+
+import (
+	"bytes"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/mmr"
+)
+
+// Gets a copy of the Merkle Mountain Range over the block hashes, in chain order.
+func (c Chain) MMR() *mmr.MMR {
+	return c.ranges().Clone()
+}
+
+// Gets the Merkle Mountain Range kept by the chain, which must not be changed.
+// Blocks must be hashed before they are appended for it to match their hashes.
+// Chains not created with New, or whose blocks were changed directly, have it built from the blocks instead.
+func (c Chain) ranges() *mmr.MMR {
+	if c.mmr != nil && c.mmr.Leaves() == c.Length() {
+		return c.mmr
+	}
+
+	m := mmr.New()
+	for _, blk := range c.Blocks {
+		m.Append(blk.GetHash())
+	}
+
+	return m
+}
+
+// Proves the block with the hash is an ancestor of the head, or the head itself.
+// The proof can be checked against the root with mmr.Verify.
+// If no block is found, error is returned.
+func (c Chain) ProveAncestor(hash []byte) (root []byte, p *mmr.Proof, err error) {
+	i, err := c.IndexOf(hash)
+	if err != nil {
+		return
+	}
+
+	m := c.ranges()
+	p, err = m.Prove(i)
+
+	return m.Root(), p, err
+}
+
+// Finds the latest block which is an ancestor of both blocks with the hashes, or one of the blocks itself.
+// Blocks in the chain and stale blocks are both searched, so side chains can be compared to the chain.
+// If either block is not found, or the blocks share no ancestor, error is returned.
+func (c Chain) CommonAncestor(a, b []byte) (*miner.Block, error) {
+	ba, ha := c.find(a)
+	bb, hb := c.find(b)
+	if ba == nil || bb == nil {
+		return nil, chainerr.ErrNotFound
+	}
+
+	// Walk the higher block down to the same height.
+	for ; ha > hb; ha-- {
+		if ba = c.parent(ha, ba); ba == nil {
+			return nil, chainerr.ErrNotFound
+		}
+	}
+
+	for ; hb > ha; hb-- {
+		if bb = c.parent(hb, bb); bb == nil {
+			return nil, chainerr.ErrNotFound
+		}
+	}
+
+	// Walk both down until they meet.
+	for h := ha; !bytes.Equal(ba.GetHash(), bb.GetHash()); h-- {
+		ba, bb = c.parent(h, ba), c.parent(h, bb)
+		if ba == nil || bb == nil {
+			return nil, chainerr.ErrNotFound
+		}
+	}
+
+	return ba, nil
+}
+
+// Checks the block with the old hash is an ancestor of the block with the new hash.
+// A block is not an ancestor of itself.
+func (c Chain) IsAncestor(old, cur []byte) bool {
+	anc, err := c.CommonAncestor(old, cur)
+
+	return err == nil && bytes.Equal(anc.GetHash(), old) && !bytes.Equal(old, cur)
+}
+
+// Finds a block in the chain, or a stale block, by its hash.
+// Returns the block and its height, or nil if no block is found.
+func (c Chain) find(hash []byte) (*miner.Block, int) {
+	if s, ok := c.lookup(hash); ok {
+		return s.blk, s.height
+	}
+
+	if i, err := c.IndexOf(hash); err == nil {
+		return c.Blocks[i], i
+	}
+
+	for h, blks := range c.stale {
+		for _, blk := range blks {
+			if bytes.Equal(blk.GetHash(), hash) {
+				return blk, h
+			}
+		}
+	}
+
+	return nil, -1
+}
+
+// Finds the parent of the block at the height, looking at the blocks one height below only.
+// Returns nil if the parent is not found.
+func (c Chain) parent(h int, blk *miner.Block) *miner.Block {
+	ph := blk.GetParentHash()
+	if s, ok := c.lookup(ph); ok && s.height == h-1 {
+		return s.blk
+	}
+
+	if prev, err := c.Get(h - 1); err == nil && bytes.Equal(prev.GetHash(), ph) {
+		return prev
+	}
+
+	for _, prev := range c.stale[h-1] {
+		if bytes.Equal(prev.GetHash(), ph) {
+			return prev
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/mmr"
+)
+
+// Test proving a block is an ancestor of the head.
+func TestProveAncestor(t *testing.T) {
+	c := createMinedChain(5)
+	h := c.Blocks[2].GetHash()
+
+	root, p, err := c.ProveAncestor(h)
+	if err != nil {
+		t.Fatalf("expected proof but got %s", err)
+	}
+
+	if !mmr.Verify(root, h, p) {
+		t.Errorf("expected proof to verify")
+	}
+
+	if mmr.Verify(root, c.Blocks[3].GetHash(), p) {
+		t.Errorf("expected proof to fail for another block")
+	}
+
+	if _, _, err := c.ProveAncestor([]byte("missing")); err == nil {
+		t.Errorf("expected proof of unknown block to return error")
+	}
+}
+
+// Test the kept range follows appends, reorgs, and rollbacks.
+func TestMMRKept(t *testing.T) {
+	c := createMinedChain(5)
+
+	// Root of a range built from the blocks.
+	built := func() []byte {
+		m := mmr.New()
+		for _, blk := range c.Blocks {
+			m.Append(blk.GetHash())
+		}
+
+		return m.Root()
+	}
+
+	f := createMinedBlock(c.Blocks[1], "Fork")
+	if err := c.Reorg(true, 2, []*miner.Block{f, createMinedBlock(f, "Fork")}); err != nil {
+		t.Fatalf("expected reorg but got %s", err)
+	}
+
+	if c.ranges() != c.mmr || !bytes.Equal(c.MMR().Root(), built()) {
+		t.Errorf("expected kept range to match the blocks after a reorg")
+	}
+
+	c.Rollback(3)
+	if c.ranges() != c.mmr || !bytes.Equal(c.MMR().Root(), built()) {
+		t.Errorf("expected kept range to match the blocks after a rollback")
+	}
+
+	// Changing the copy leaves the kept range alone.
+	c.MMR().Append([]byte("x"))
+	if c.mmr.Leaves() != c.Length() {
+		t.Errorf("expected kept range to be unchanged by its copy")
+	}
+}
+
+// Test finding the common ancestor of blocks, including stale blocks.
+func TestCommonAncestor(t *testing.T) {
+	c := createMinedChain(4)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	// Fork from the second block, making the old blocks stale.
+	f1 := miner.New(old[1], 1, "Fork")
+	f1.Mine()
+	f1.GenerateHash(true)
+	f2 := miner.New(f1, 1, "Fork")
+	f2.Mine()
+	f2.GenerateHash(true)
+	if err := c.Reorg(true, 2, []*miner.Block{f1, f2}); err != nil {
+		t.Fatalf("expected reorg but got %s", err)
+	}
+
+	for _, tc := range []struct {
+		a, b *miner.Block
+		e    *miner.Block
+	}{
+		{old[3], f2, old[1]},
+		{f2, old[2], old[1]},
+		{old[0], f2, old[0]},
+		{f1, f2, f1},
+		{f2, f2, f2},
+	} {
+		anc, err := c.CommonAncestor(tc.a.GetHash(), tc.b.GetHash())
+		if err != nil || anc != tc.e {
+			t.Errorf("expected common ancestor %s but got %v", tc.e.GetHash(), err)
+		}
+	}
+
+	if _, err := c.CommonAncestor([]byte("missing"), f2.GetHash()); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
+// Test checking a block is an ancestor of another.
+func TestIsAncestor(t *testing.T) {
+	c := createMinedChain(3)
+	h0, h2 := c.Blocks[0].GetHash(), c.Blocks[2].GetHash()
+
+	if !c.IsAncestor(h0, h2) {
+		t.Errorf("expected genesis to be an ancestor of the head")
+	}
+
+	if c.IsAncestor(h2, h0) {
+		t.Errorf("expected head to not be an ancestor of genesis")
+	}
+
+	if c.IsAncestor(h2, h2) {
+		t.Errorf("expected block to not be an ancestor of itself")
+	}
+
+	// Side chain block is not an ancestor of the chain.
+	blk := miner.New(c.Blocks[0], 1, "Fork")
+	blk.Mine()
+	blk.GenerateHash(true)
+	c.addStale(1, blk)
+
+	if c.IsAncestor(blk.GetHash(), h2) || !c.IsAncestor(h0, blk.GetHash()) {
+		t.Errorf("expected side chain block to only descend from genesis")
+	}
+}
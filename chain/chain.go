@@ -1,21 +1,70 @@
 package chain
 
 import (
-	"errors"
+	"bytes"
+	"fmt"
+	"time"
 
 	"encoding/json"
 
+	"github.com/ohmybrew/gochain/chainerr"
 	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/mmr"
 )
 
+// Read access to a chain, implemented by Chain.
+// Allows applications to swap the chain they read from without code changes.
+type Reader interface {
+	Length() int
+	Height() int
+	Head() (*miner.Block, error)
+	Get(i int) (*miner.Block, error)
+	GetByHeight(h int) (*miner.Block, error)
+	GetByHash(hash []byte) (*miner.Block, error)
+	IndexOf(hash []byte) (int, error)
+	Previous(i int) (*miner.Block, error)
+	Next(i int) (*miner.Block, error)
+	Last() (*miner.Block, error)
+	First() (*miner.Block, error)
+	Page(after []byte, limit int) ([]*miner.Block, []byte, error)
+	IsFinal(hash []byte, depth int) bool
+	CommonAncestor(a, b []byte) (*miner.Block, error)
+	IsAncestor(old, cur []byte) bool
+	IsValid() bool
+	Encode() []byte
+}
+
+// Chain must satisfy the reader.
+var _ Reader = Chain{}
+
+// Consensus parameters of a chain, which can be scheduled to change at a height.
+// Every node must use the same spec, so they all switch parameters at the same block.
+type Spec interface {
+	DifficultyAt(h int) int
+	MinIntervalAt(h int) time.Duration
+}
+
 // Reprecents a blockchain.
 type Chain struct {
-	Blocks []*miner.Block `json:"blocks"`
+	Blocks      []*miner.Block `json:"blocks"`
+	ID          int            `json:"-"` // Chain ID, blocks from other chains are rejected.
+	Finality    Finality       `json:"-"` // Rule to determine finality, DefaultFinality is used if nil.
+	MinInterval time.Duration  `json:"-"` // Minimum time between a block and the previous block.
+	MaxReorg    int            `json:"-"` // Maximum blocks a reorg can remove, unlimited if zero.
+	Spec        Spec           `json:"-"` // Consensus parameters by height, used over MinInterval if set.
+
+	feed    *feed                  // Subscribers to chain changes.
+	stale   map[int][]*miner.Block // Blocks removed from the chain, by index.
+	store   map[string]stored      // Blocks of the chain and side chains, by hash.
+	base    map[string]stored      // Store overlaid while a reorg is checked, read but never written.
+	mmr     *mmr.MMR               // Merkle Mountain Range over the block hashes, kept as blocks are appended and removed.
+	invalid map[string]bool        // Blocks marked invalid, by hash.
 }
 
 // Creates a new chain.
+// The chain's feed is created up front, so subscribing is safe while another goroutine changes the chain.
 func New() *Chain {
-	return new(Chain)
+	return &Chain{feed: new(feed), mmr: mmr.New()}
 }
 
 // Encodes the struct to JSON format.
@@ -37,12 +86,29 @@ func (c Chain) Get(i int) (*miner.Block, error) {
 
 	if ct == 0 || i > (ct-1) || i < 0 {
 		// Out of range.
-		return nil, errors.New("no block found")
+		return nil, chainerr.ErrNotFound
 	}
 
 	return c.Blocks[i], nil
 }
 
+// Gets the index of a block by its hash.
+// If no block is found, error is returned.
+func (c Chain) IndexOf(hash []byte) (int, error) {
+	// Stored blocks know their height, check the chain has it there.
+	if s, ok := c.lookup(hash); ok && c.isCanonical(s.height, s.blk) {
+		return s.height, nil
+	}
+
+	for i, blk := range c.Blocks {
+		if bytes.Equal(blk.GetHash(), hash) {
+			return i, nil
+		}
+	}
+
+	return -1, chainerr.ErrNotFound
+}
+
 // Gets the previous block relative to the provided index.
 // If no available previous block is found, error is returned.
 func (c Chain) Previous(i int) (*miner.Block, error) {
@@ -71,23 +137,212 @@ func (c Chain) First() (*miner.Block, error) {
 // Will return error if block is invalid and validation was asked for.
 func (c *Chain) Append(ver bool, blk *miner.Block) error {
 	// Verify the block if asked to verify by argument one.
-	if blk.Miner == nil || (ver && !blk.Miner.IsValid()) {
-		return errors.New("can not store block to chain, miner is not valid")
+	if blk.Miner == nil {
+		return fmt.Errorf("can not store block to chain, %w", chainerr.ErrInvalidMiner)
+	}
+
+	// Always reject blocks from other chains, preventing replays across networks.
+	if blk.GetChainID() != c.ID {
+		return fmt.Errorf("can not store block to chain, %w", chainerr.ErrWrongChain)
+	}
+
+	// Reject blocks already in the chain, or marked invalid.
+	if h := blk.GetHash(); h != nil {
+		if _, err := c.IndexOf(h); err == nil {
+			return fmt.Errorf("can not store block to chain, %w", chainerr.ErrKnownBlock)
+		}
+
+		if c.invalid[string(h)] {
+			return fmt.Errorf("can not store block to chain, %w", chainerr.ErrInvalidBlock)
+		}
+	}
+
+	if ver {
+		if err := Validate(blk); err != nil {
+			return fmt.Errorf("can not store block to chain, %w", err)
+		}
+
+		if err := c.ValidateDifficulty(blk); err != nil {
+			return fmt.Errorf("can not store block to chain, %w", err)
+		}
+
+		if prev, err := c.Last(); err == nil {
+			// Test the block is built on the head of the chain.
+			if !bytes.Equal(blk.GetParentHash(), prev.GetHash()) {
+				return fmt.Errorf("can not store block to chain, %w", chainerr.ErrOrphanBlock)
+			}
+
+			if err := c.ValidateInterval(prev, blk); err != nil {
+				return fmt.Errorf("can not store block to chain, %w", err)
+			}
+		}
 	}
 
 	// All good, append.
 	c.Blocks = append(c.Blocks, blk)
+	if c.mmr != nil {
+		c.mmr.Append(blk.GetHash())
+	}
+	c.put(blk, c.Height())
+	c.removeStale(c.Height(), blk)
+	c.emit(Event{Block: blk, Index: c.Length() - 1})
+	c.emitHead(Head{})
+
+	return nil
+}
+
+// Rolls the chain back, removing all blocks from the index onwards.
+// Subscribers will receive a removed event for each block, starting from the last block.
+// If any of the blocks are final, the rollback is refused and error is returned.
+func (c *Chain) Rollback(i int) error {
+	if _, err := c.Get(i); err != nil {
+		return err
+	}
+
+	if err := c.checkFinal(i); err != nil {
+		return err
+	}
+
+	rm := c.rollback(i)
+	c.emitRemoved(i, rm)
+
+	anc, _ := c.Get(i - 1)
+	c.emitHead(Head{Reorg: true, Ancestor: anc, Removed: rm})
 
 	return nil
 }
 
+// Reorganizes the chain, replacing all blocks from the index onwards with the new blocks.
+// The new blocks are appended in order, if any fail the chain is left untouched and error is returned.
+// Subscribers will receive removed events for the old blocks followed by events for the new blocks.
+// If the reorg would remove more blocks than the max reorg, it is refused and an alert is raised.
+// If the reorg would remove a final block, it is refused and error is returned.
+func (c *Chain) Reorg(ver bool, i int, blks []*miner.Block) error {
+	if i < 0 || i > c.Length() {
+		return chainerr.ErrNotFound
+	}
+
+	if err := c.checkFinal(i); err != nil {
+		return err
+	}
+
+	// Append the new blocks to a copy of the chain up to the index.
+	// The copy stores the new blocks in an overlay, so the store is untouched unless the reorg succeeds.
+	tmp := *c
+	tmp.feed, tmp.stale = nil, nil
+	tmp.store, tmp.base = nil, c.store
+	tmp.mmr = nil
+	tmp.Blocks = c.Blocks[:i:i]
+	for _, blk := range blks {
+		if err := tmp.Append(ver, blk); err != nil {
+			return err
+		}
+	}
+
+	// Refuse deep reorgs, the network may be under attack.
+	if err := c.checkDepth(i); err != nil {
+		anc, _ := c.Get(i - 1)
+		head, _ := tmp.Last()
+		c.emitAlert(Alert{Block: head, Ancestor: anc, Depth: c.Length() - i, Max: c.MaxReorg})
+
+		return err
+	}
+
+	// All good, swap the blocks.
+	rm := c.rollback(i)
+	c.emitRemoved(i, rm)
+
+	c.Blocks = tmp.Blocks
+	for h, s := range tmp.store {
+		c.putStored(h, s)
+	}
+	for j := i; j < c.Length(); j++ {
+		if c.mmr != nil {
+			c.mmr.Append(c.Blocks[j].GetHash())
+		}
+		c.removeStale(j, c.Blocks[j])
+		c.emit(Event{Block: c.Blocks[j], Index: j})
+	}
+
+	anc, _ := c.Get(i - 1)
+	c.emitHead(Head{Reorg: true, Ancestor: anc, Removed: rm})
+
+	return nil
+}
+
+// Checks a reorg from the index would not remove more blocks than the max reorg.
+func (c Chain) checkDepth(i int) error {
+	if d := c.Length() - i; c.MaxReorg > 0 && d > c.MaxReorg {
+		return fmt.Errorf("%w, %d blocks would be removed", chainerr.ErrReorgTooDeep, d)
+	}
+
+	return nil
+}
+
+// Removes all blocks from the index onwards.
+// Returns the removed blocks, starting from the last block.
+func (c *Chain) rollback(i int) (rm []*miner.Block) {
+	for j := c.Length() - 1; j >= i; j-- {
+		rm = append(rm, c.Blocks[j])
+		c.addStale(j, c.Blocks[j])
+		c.Blocks[j] = nil
+		c.Blocks = c.Blocks[:j]
+	}
+
+	if c.mmr != nil {
+		c.mmr.Truncate(i)
+	}
+
+	return
+}
+
+// Sends the removed events for blocks removed from the index onwards.
+func (c *Chain) emitRemoved(i int, rm []*miner.Block) {
+	for j, blk := range rm {
+		c.emit(Event{Block: blk, Index: i + len(rm) - 1 - j, Removed: true})
+	}
+}
+
 // Walks the chain to ensure all blocks are valid.
 func (c Chain) IsValid() bool {
-	for _, blk := range c.Blocks {
-		if !blk.Miner.IsValid() {
+	for i, blk := range c.Blocks {
+		if Validate(blk) != nil || c.ValidateDifficulty(blk) != nil {
+			return false
+		}
+
+		if i > 0 && c.ValidateInterval(c.Blocks[i-1], blk) != nil {
 			return false
 		}
 	}
 
 	return true
 }
+
+// Checks the block has the difficulty the spec schedules for its height.
+// Any difficulty is accepted if the chain has no spec.
+func (c Chain) ValidateDifficulty(blk *miner.Block) error {
+	if c.Spec == nil {
+		return nil
+	}
+
+	if h := blk.GetIndex(); blk.GetDifficulty() != c.Spec.DifficultyAt(h) {
+		return fmt.Errorf("%w, expected a difficulty of %d at height %d", chainerr.ErrBadDifficulty, c.Spec.DifficultyAt(h), h)
+	}
+
+	return nil
+}
+
+// Checks the block was not mined too soon after the previous block.
+// The spec's interval for the block's height is used if the chain has a spec.
+func (c Chain) ValidateInterval(prev, blk *miner.Block) error {
+	iv := c.MinInterval
+	if c.Spec != nil {
+		iv = c.Spec.MinIntervalAt(blk.GetIndex())
+	}
+
+	if blk.GetTimestamp().Sub(prev.GetTimestamp()) < iv {
+		return fmt.Errorf("%w, block was mined too soon after the previous block", chainerr.ErrBadTimestamp)
+	}
+
+	return nil
+}
@@ -1,9 +1,13 @@
 package chain
 
 import (
+	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/ohmybrew/gochain/chainerr"
+
 	"github.com/ohmybrew/gochain/miner"
 )
 
@@ -89,6 +93,24 @@ func TestAppendToChain(t *testing.T) {
 	}
 }
 
+// Test a long chain of default mined blocks appends with validation.
+// Some blocks are solved by a PoW of 0, which must count as mined.
+func TestAppendToChainMined(t *testing.T) {
+	c := New()
+
+	var prev *miner.Block
+	for i := 0; i < 32; i++ {
+		blk := miner.New(prev, 1, "")
+		blk.Mine()
+		blk.GenerateHash(true)
+
+		if err := c.Append(true, blk); err != nil {
+			t.Fatalf("expected block %d to be appended but got %s", i, err)
+		}
+		prev = blk
+	}
+}
+
 // Test append to chain with invalid.
 func TestAppendToChainWithInvalid(t *testing.T) {
 	// No parent.
@@ -110,6 +132,130 @@ func TestAppendToChainWithInvalid(t *testing.T) {
 	}
 }
 
+// Test append to chain with another chain's block.
+func TestAppendToChainWithOtherChainID(t *testing.T) {
+	c := New()
+	c.ID = 1
+
+	blk := miner.New(nil, 1, "One")
+	if err := c.Append(false, blk); err == nil {
+		t.Errorf("expected append of block from another chain to fail but resulted in success")
+	}
+
+	(blk.Miner).(*miner.Chunk).ChainID = 1
+	if err := c.Append(false, blk); err != nil {
+		t.Errorf("expected append of block from the same chain to succeed but got %s", err)
+	}
+
+	// Chain ID is inherited by the next block.
+	blk2 := miner.New(blk, 1, "Two")
+	if err := c.Append(false, blk2); err != nil {
+		t.Errorf("expected append of child block to succeed but got %s", err)
+	}
+}
+
+// Spec which raises the difficulty by one every height.
+type rampSpec struct{}
+
+func (rampSpec) DifficultyAt(h int) int            { return h }
+func (rampSpec) MinIntervalAt(h int) time.Duration { return 0 }
+
+// Test append to chain with a difficulty the spec does not schedule.
+func TestAppendToChainWithSpec(t *testing.T) {
+	c := New()
+	c.Spec = rampSpec{}
+
+	blk := miner.New(nil, 0, "Zero")
+	blk.GenerateHash(true)
+	if err := c.Append(true, blk); err != nil {
+		t.Fatalf("expected block to be appended but got %s", err)
+	}
+
+	blk2 := miner.New(blk, 2, "One")
+	blk2.Mine()
+	blk2.GenerateHash(true)
+	if err := c.Append(true, blk2); !errors.Is(err, chainerr.ErrBadDifficulty) {
+		t.Errorf("expected bad difficulty error but got %v", err)
+	}
+
+	blk2 = miner.New(blk, 1, "One")
+	blk2.Mine()
+	blk2.GenerateHash(true)
+	if err := c.Append(true, blk2); err != nil || !c.IsValid() {
+		t.Errorf("expected block to be appended but got %v", err)
+	}
+}
+
+// Test append to chain with a block mined too soon.
+func TestAppendToChainWithMinInterval(t *testing.T) {
+	c := New()
+	c.MinInterval = time.Minute
+
+	blk := miner.New(nil, 1, "One")
+	blk2 := miner.New(blk, 1, "Two")
+	ck := (blk.Miner).(*miner.Chunk)
+	ck2 := (blk2.Miner).(*miner.Chunk)
+	ck2.Timestamp = ck.Timestamp + time.Second.Milliseconds()
+
+	for _, b := range []*miner.Block{blk, blk2} {
+		b.Mine()
+		b.GenerateHash(true)
+	}
+
+	if err := c.Append(true, blk); err != nil {
+		t.Fatalf("expected genesis block to be appended but got %s", err)
+	}
+
+	if err := c.Append(true, blk2); err == nil {
+		t.Errorf("expected block mined too soon to be rejected")
+	}
+
+	ck2.Timestamp = ck.Timestamp + time.Minute.Milliseconds()
+	blk2.GenerateHash(true)
+	if err := c.Append(true, blk2); err != nil {
+		t.Errorf("expected block to be appended but got %s", err)
+	}
+
+	if !c.IsValid() {
+		t.Errorf("expected chain to validate but failed")
+	}
+
+	c.MinInterval = time.Hour
+	if c.IsValid() {
+		t.Errorf("expected chain to be invalid with a longer interval")
+	}
+}
+
+// Test append to chain returns errors which can be branched on.
+func TestAppendToChainErrors(t *testing.T) {
+	c := createMinedChain(2)
+	blks := c.Blocks
+
+	// Already in the chain.
+	if err := c.Append(true, blks[1]); !errors.Is(err, chainerr.ErrKnownBlock) {
+		t.Errorf("expected known block error but got %v", err)
+	}
+
+	// Not built on the head.
+	blk := miner.New(blks[0], 1, "Fork")
+	blk.Mine()
+	blk.GenerateHash(true)
+	if err := c.Append(true, blk); !errors.Is(err, chainerr.ErrOrphanBlock) {
+		t.Errorf("expected orphan block error but got %v", err)
+	}
+
+	// Not mined.
+	blk = miner.New(blks[1], 1, "Three")
+	if err := c.Append(true, blk); !errors.Is(err, chainerr.ErrInvalidPoW) {
+		t.Errorf("expected invalid PoW error but got %v", err)
+	}
+
+	// Missing.
+	if _, err := c.Get(5); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
 // Test chain validates.
 func TestValidChain(t *testing.T) {
 	// New chain.
@@ -156,7 +302,7 @@ func TestChainEncode(t *testing.T) {
 	blk := &miner.Block{
 		Miner: &miner.Chunk{
 			Parent:     nil,
-			Timestamp:  time.Now(),
+			Timestamp:  time.Now().UnixMilli(),
 			Index:      1,
 			Difficulty: 1,
 			Data:       "Hello World",
@@ -170,7 +316,7 @@ func TestChainEncode(t *testing.T) {
 
 	// Actual and expected.
 	a := string(c.Encode())
-	e := "{\"blocks\":[{\"parent_hash\":null,\"hash\":null,\"index\":1,\"pow\":0,\"difficulty\":1,\"data\":\"Hello World\",\"timestamp\":\"" + ck.Timestamp.Format(time.RFC3339Nano) + "\"}]}"
+	e := "{\"blocks\":[{\"parent_hash\":null,\"hash\":null,\"index\":1,\"pow\":0,\"difficulty\":1,\"data\":\"Hello World\",\"timestamp\":" + strconv.FormatInt(ck.Timestamp, 10) + "}]}"
 
 	if a != e {
 		t.Errorf("expected encode of %s but got %s", a, e)
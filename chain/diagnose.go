@@ -0,0 +1,106 @@
+package chain
+
+import (
+	"bytes"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+type (
+	// Reprecents the result of a rule checked against a block.
+	Check struct {
+		Rule   string `json:"rule"`
+		Pass   bool   `json:"pass"`
+		Detail string `json:"detail,omitempty"` // Reason the rule failed.
+	}
+
+	// Reprecents the result of every rule checked against a block.
+	Report struct {
+		Checks []Check `json:"checks"`
+	}
+)
+
+// Adds the result of a rule to the report.
+func (r *Report) add(rule string, err error) {
+	ck := Check{Rule: rule, Pass: err == nil}
+	if err != nil {
+		ck.Detail = err.Error()
+	}
+
+	r.Checks = append(r.Checks, ck)
+}
+
+// Determines if every rule passed.
+func (r Report) IsValid() bool {
+	return len(r.Failed()) == 0
+}
+
+// Gets the rules which failed.
+func (r Report) Failed() (f []Check) {
+	for _, ck := range r.Checks {
+		if !ck.Pass {
+			f = append(f, ck)
+		}
+	}
+
+	return
+}
+
+// Checks the block against every rule of the chain, without stopping at the first failure.
+// If the block is in the chain it is checked against the block before it,
+// otherwise it is checked as if it were being appended to the chain.
+func (c Chain) Diagnose(blk *miner.Block) (r Report) {
+	if blk == nil || blk.Miner == nil {
+		r.add("miner", chainerr.ErrInvalidMiner)
+
+		return
+	}
+	r.add("miner", nil)
+
+	// Find the block it should follow.
+	var prev *miner.Block
+	if i, err := c.IndexOf(blk.GetHash()); blk.GetHash() != nil && err == nil {
+		// In the chain, check against the block before it.
+		prev, _ = c.Get(i - 1)
+	} else {
+		// Not in the chain, check as the next block.
+		prev, _ = c.Last()
+	}
+
+	var cerr error
+	if blk.GetChainID() != c.ID {
+		cerr = chainerr.ErrWrongChain
+	}
+	r.add("chain_id", cerr)
+
+	// Blocks marked invalid, or built on one, are rejected by the chain.
+	var ierr error
+	if c.IsInvalidated(blk.GetHash()) || c.IsInvalidated(blk.GetParentHash()) {
+		ierr = chainerr.ErrInvalidBlock
+	}
+	r.add("invalidated", ierr)
+
+	// Report each header rule on its own when the miner can check them separately.
+	if hc, ok := blk.Miner.(interface{ CheckHeader() []miner.HeaderCheck }); ok {
+		for _, ck := range hc.CheckHeader() {
+			r.add("header_"+ck.Rule, ck.Err)
+		}
+	} else {
+		r.add("header", ValidateHeader(blk))
+	}
+
+	r.add("body", ValidateBody(blk))
+	r.add("difficulty", c.ValidateDifficulty(blk))
+
+	if prev != nil {
+		var perr error
+		if !bytes.Equal(blk.GetParentHash(), prev.GetHash()) {
+			perr = chainerr.ErrOrphanBlock
+		}
+		r.add("parent", perr)
+		r.add("interval", c.ValidateInterval(prev, blk))
+	}
+
+	return
+}
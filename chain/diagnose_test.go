@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test a valid block passes every rule.
+func TestDiagnoseValid(t *testing.T) {
+	c := createMinedChain(2)
+
+	r := c.Diagnose(c.Blocks[1])
+	if !r.IsValid() || len(r.Checks) != 13 {
+		t.Errorf("expected every rule to pass but got %v", r.Checks)
+	}
+
+	// Genesis has no previous block to check against.
+	if r := c.Diagnose(c.Blocks[0]); !r.IsValid() || len(r.Checks) != 7 {
+		t.Errorf("expected every rule to pass but got %v", r.Checks)
+	}
+}
+
+// Test every failing rule is reported.
+func TestDiagnoseInvalid(t *testing.T) {
+	c := createMinedChain(2)
+	c.ID = 1
+
+	// Forked from genesis, not mined, and on another chain.
+	blk := miner.New(c.Blocks[0], 1, "Fork")
+	blk.GenerateHash(true)
+
+	f := c.Diagnose(blk).Failed()
+	rules := map[string]bool{}
+	for _, ck := range f {
+		rules[ck.Rule] = true
+		if ck.Detail == "" {
+			t.Errorf("expected failed rule %s to have detail", ck.Rule)
+		}
+	}
+
+	for _, e := range []string{"chain_id", "header_pow", "parent"} {
+		if !rules[e] {
+			t.Errorf("expected rule %s to fail", e)
+		}
+	}
+
+	if len(f) != 3 {
+		t.Errorf("expected 3 failed rules but got %v", f)
+	}
+
+	// Header rules are reported separately, so failing two reports both.
+	blk2 := miner.New(c.Blocks[1], 1, "Tagged")
+	blk2.Miner.(*miner.Chunk).ExtraData = make([]byte, miner.MaxExtraData+1)
+	blk2.GenerateHash(true)
+
+	rules = map[string]bool{}
+	for _, ck := range c.Diagnose(blk2).Failed() {
+		rules[ck.Rule] = true
+	}
+
+	if !rules["header_extra_data"] || !rules["header_pow"] || len(rules) != 3 {
+		t.Errorf("expected extra data and PoW header rules to fail but got %v", rules)
+	}
+
+	if r := c.Diagnose(nil); r.IsValid() || r.Checks[0].Rule != "miner" {
+		t.Errorf("expected missing miner to fail")
+	}
+}
+
+// Test blocks marked invalid, and blocks built on them, fail the invalidated rule.
+func TestDiagnoseInvalidated(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+	if err := c.InvalidateBlock(old[1].GetHash()); err != nil {
+		t.Fatalf("expected block to be invalidated but got %s", err)
+	}
+
+	for _, blk := range []*miner.Block{old[1], old[2], createMinedBlock(old[2], "Child")} {
+		f := c.Diagnose(blk).Failed()
+		if len(f) == 0 || f[0].Rule != "invalidated" {
+			t.Errorf("expected invalidated rule to fail but got %v", f)
+		}
+	}
+
+	if err := c.ReconsiderBlock(old[1].GetHash()); err != nil {
+		t.Fatalf("expected block to be reconsidered but got %s", err)
+	}
+
+	if r := c.Diagnose(old[2]); !r.IsValid() {
+		t.Errorf("expected reconsidered block to pass but got %v", r.Failed())
+	}
+}
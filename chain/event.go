@@ -0,0 +1,215 @@
+package chain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+type (
+	// Reprecents a change to the chain.
+	// Removed is set when the block was undone by a rollback or reorg.
+	Event struct {
+		Block   *miner.Block `json:"block"`
+		Index   int          `json:"index"`
+		Removed bool         `json:"removed"`
+	}
+
+	// Reprecents a change of the chain's head.
+	// Block will be nil if the chain was emptied.
+	// On a rollback or reorg, Ancestor is the common ancestor of the old and new head
+	// (nil if the genesis block was replaced), and Removed holds the undone blocks, last block first.
+	Head struct {
+		Block    *miner.Block   `json:"block"`
+		Index    int            `json:"index"`
+		Reorg    bool           `json:"reorg"`
+		Ancestor *miner.Block   `json:"ancestor,omitempty"`
+		Removed  []*miner.Block `json:"removed,omitempty"`
+	}
+
+	// Reprecents a reorg which was refused for being deeper than the chain allows.
+	// Block is the head the reorg would have switched to.
+	Alert struct {
+		Block    *miner.Block `json:"block"`
+		Ancestor *miner.Block `json:"ancestor,omitempty"`
+		Depth    int          `json:"depth"`
+		Max      int          `json:"max"`
+	}
+
+	// Subscribers to chain changes.
+	feed struct {
+		mu     sync.Mutex
+		subs   []chan Event
+		heads  []chan Head
+		alerts []chan Alert
+	}
+)
+
+// Guards the feed of chains which were not created with New, whose feed is created lazily.
+var feedMu sync.Mutex
+
+// Gets the feed, creating it if needed.
+func (c *Chain) getFeed() *feed {
+	feedMu.Lock()
+	defer feedMu.Unlock()
+
+	if c.feed == nil {
+		c.feed = new(feed)
+	}
+
+	return c.feed
+}
+
+// Gets the feed, or nil if there are no subscribers yet.
+func (c *Chain) loadFeed() *feed {
+	feedMu.Lock()
+	defer feedMu.Unlock()
+
+	return c.feed
+}
+
+// Subscribes to chain events with a buffer size.
+// Events are sent in order, so the subscriber must keep receiving or unsubscribe,
+// otherwise changes to the chain will block.
+func (c *Chain) Subscribe(buf int) <-chan Event {
+	f := c.getFeed()
+	ch := make(chan Event, buf)
+
+	f.mu.Lock()
+	f.subs = append(f.subs, ch)
+	f.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribes from chain events and closes the channel.
+func (c *Chain) Unsubscribe(ch <-chan Event) {
+	f := c.getFeed()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, sub := range f.subs {
+		if sub == ch {
+			f.subs = append(f.subs[:i], f.subs[i+1:]...)
+			close(sub)
+
+			return
+		}
+	}
+}
+
+// Subscribes to alerts with a buffer size.
+// Alerts are sent as they are raised, so the subscriber must keep receiving or unsubscribe,
+// otherwise refused reorgs will block.
+func (c *Chain) SubscribeAlerts(buf int) <-chan Alert {
+	f := c.getFeed()
+	ch := make(chan Alert, buf)
+
+	f.mu.Lock()
+	f.alerts = append(f.alerts, ch)
+	f.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribes from alerts and closes the channel.
+func (c *Chain) UnsubscribeAlerts(ch <-chan Alert) {
+	f := c.getFeed()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, sub := range f.alerts {
+		if sub == ch {
+			f.alerts = append(f.alerts[:i], f.alerts[i+1:]...)
+			close(sub)
+
+			return
+		}
+	}
+}
+
+// Watches for changes of the chain's head until the context is done.
+// Updates are sent in order, so the watcher must keep receiving until the context is done,
+// otherwise changes to the chain will block. The channel is closed once the context is done.
+func (c *Chain) WatchHead(ctx context.Context) <-chan Head {
+	f := c.getFeed()
+	ch := make(chan Head)
+
+	f.mu.Lock()
+	f.heads = append(f.heads, ch)
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		// Keep receiving so a pending update can not block removing the watcher.
+		go func() {
+			for range ch {
+			}
+		}()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		for i, h := range f.heads {
+			if h == ch {
+				f.heads = append(f.heads[:i], f.heads[i+1:]...)
+				close(ch)
+
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Sends the event to all subscribers.
+func (c *Chain) emit(e Event) {
+	f := c.loadFeed()
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, sub := range f.subs {
+		sub <- e
+	}
+}
+
+// Sends the head update of the chain to all head watchers.
+func (c *Chain) emitHead(h Head) {
+	f := c.loadFeed()
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h.Index = c.Length() - 1
+	h.Block, _ = c.Last()
+	for _, ch := range f.heads {
+		ch <- h
+	}
+}
+
+// Sends the alert to all alert subscribers.
+func (c *Chain) emitAlert(a Alert) {
+	f := c.loadFeed()
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, ch := range f.alerts {
+		ch <- a
+	}
+}
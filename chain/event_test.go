@@ -0,0 +1,214 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test subscribers receive appended blocks.
+func TestSubscribeAppend(t *testing.T) {
+	c := New()
+	ch := c.Subscribe(2)
+
+	blks := createFakeChain().Blocks
+	c.Append(false, blks[0])
+	c.Append(false, blks[1])
+
+	for i, blk := range blks {
+		e := <-ch
+		if e.Block != blk || e.Index != i || e.Removed {
+			t.Errorf("expected event for block %d to be an append", i)
+		}
+	}
+}
+
+// Test subscribers receive removed events on rollback, last block first.
+func TestSubscribeRollback(t *testing.T) {
+	c := createFakeChain()
+	blks := append(c.Blocks[:0:0], c.Blocks...)
+	ch := c.Subscribe(2)
+
+	if err := c.Rollback(0); err != nil {
+		t.Fatalf("expected rollback to succeed but got %s", err)
+	}
+
+	if c.Length() != 0 {
+		t.Errorf("expected chain length to be 0, got %d", c.Length())
+	}
+
+	for i := len(blks) - 1; i >= 0; i-- {
+		e := <-ch
+		if e.Block != blks[i] || e.Index != i || !e.Removed {
+			t.Errorf("expected event for block %d to be a removal", i)
+		}
+	}
+
+	if err := c.Rollback(0); err == nil {
+		t.Errorf("expected rollback of an empty chain to fail")
+	}
+}
+
+// Test unsubscribing closes the channel.
+func TestUnsubscribe(t *testing.T) {
+	c := New()
+	ch := c.Subscribe(0)
+	c.Unsubscribe(ch)
+
+	if _, ok := <-ch; ok {
+		t.Errorf("expected channel to be closed")
+	}
+
+	// No subscribers, should not block.
+	c.Append(false, createFakeChain().Blocks[0])
+}
+
+// Test reorg replaces blocks and sends removed events before the new blocks.
+func TestReorg(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+	ch := c.Subscribe(4)
+
+	// Fork from the first block.
+	blk := miner.New(old[0], 1, "Fork")
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	if err := c.Reorg(true, 1, []*miner.Block{blk}); err != nil {
+		t.Fatalf("expected reorg to succeed but got %s", err)
+	}
+
+	if c.Length() != 2 || c.Blocks[1] != blk {
+		t.Errorf("expected fork block to be the head")
+	}
+
+	es := []Event{
+		{Block: old[2], Index: 2, Removed: true},
+		{Block: old[1], Index: 1, Removed: true},
+		{Block: blk, Index: 1},
+	}
+	for _, e := range es {
+		if a := <-ch; a != e {
+			t.Errorf("expected event %v but got %v", e, a)
+		}
+	}
+}
+
+// Test a reorg with invalid blocks leaves the chain untouched.
+func TestReorgWithInvalid(t *testing.T) {
+	c := createMinedChain(2)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	bad := miner.New(old[0], 1, "Bad") // Not mined.
+	if err := c.Reorg(true, 1, []*miner.Block{bad}); err == nil {
+		t.Errorf("expected reorg with an invalid block to fail")
+	}
+
+	if c.Length() != 2 || c.Blocks[1] != old[1] {
+		t.Errorf("expected chain to be untouched")
+	}
+
+	if err := c.Reorg(true, 3, nil); err == nil {
+		t.Errorf("expected reorg past the head to fail")
+	}
+}
+
+// Test head watchers receive the common ancestor on reorg.
+func TestWatchHead(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := c.WatchHead(ctx)
+
+	blk := miner.New(old[0], 1, "Fork")
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	done := make(chan struct{})
+	go func() {
+		c.Reorg(true, 1, []*miner.Block{blk})
+		c.Rollback(1)
+		close(done)
+	}()
+
+	h := <-ch
+	if h.Block != blk || h.Index != 1 || !h.Reorg || h.Ancestor != old[0] || len(h.Removed) != 2 || h.Removed[0] != old[2] {
+		t.Errorf("expected reorg head update with the common ancestor but got %v", h)
+	}
+
+	h = <-ch
+	if h.Block != old[0] || h.Index != 0 || h.Ancestor != old[0] || h.Removed[0] != blk {
+		t.Errorf("expected rollback head update but got %v", h)
+	}
+
+	<-done
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Errorf("expected channel to be closed once the context is done")
+	}
+
+	// No watchers, should not block.
+	c.Append(false, blk)
+}
+
+// Test reorgs deeper than the max are refused with an alert.
+func TestReorgMaxDepth(t *testing.T) {
+	c := createMinedChain(4)
+	c.MaxReorg = 2
+	ch := c.SubscribeAlerts(1)
+
+	f1 := createMinedBlock(c.Blocks[0], "Fork")
+	f2 := createMinedBlock(f1, "Fork")
+	f3 := createMinedBlock(f2, "Fork")
+	f4 := createMinedBlock(f3, "Fork")
+
+	err := c.Reorg(true, 1, []*miner.Block{f1, f2, f3, f4})
+	if !errors.Is(err, chainerr.ErrReorgTooDeep) {
+		t.Errorf("expected reorg too deep error but got %v", err)
+	}
+
+	if a := <-ch; a.Block != f4 || a.Ancestor != c.Blocks[0] || a.Depth != 3 || a.Max != 2 {
+		t.Errorf("expected alert for a reorg of 3 blocks but got %+v", a)
+	}
+
+	if c.Length() != 4 || c.Blocks[1] == f1 {
+		t.Errorf("expected chain to be untouched")
+	}
+
+	// Reorgs up to the max are allowed.
+	f := createMinedBlock(c.Blocks[1], "Fork")
+	if err := c.Reorg(true, 2, []*miner.Block{f}); err != nil {
+		t.Errorf("expected reorg of 2 blocks but got %s", err)
+	}
+
+	c.UnsubscribeAlerts(ch)
+	if _, ok := <-ch; ok {
+		t.Errorf("expected channel to be closed")
+	}
+}
+
+// Test subscribing is safe while another goroutine changes the chain.
+func TestSubscribeConcurrent(t *testing.T) {
+	c := New()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var prev *miner.Block
+		for i := 0; i < 50; i++ {
+			blk := miner.New(prev, 0, "")
+			c.Append(false, blk)
+			prev = blk
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		ch := c.Subscribe(100)
+		c.Unsubscribe(ch)
+	}
+	<-done
+}
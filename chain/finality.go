@@ -0,0 +1,95 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+type (
+	// Rule which determines if the block at the index is final and irreversible.
+	// Rollbacks, reorgs and invalidations which would remove a final block are refused.
+	Finality interface {
+		IsFinal(c Chain, i int) bool
+	}
+
+	// Finality rule where a block is final once it has N blocks built on top of it.
+	Confirmations int
+
+	// Reprecents a known good block.
+	Checkpoint struct {
+		Index int    `json:"index"`
+		Hash  []byte `json:"hash"`
+	}
+
+	// Finality rule where a block is final once it is at or behind a checkpoint in the chain.
+	Checkpoints []Checkpoint
+)
+
+// Finality rule used by a chain without one.
+var DefaultFinality Finality = Confirmations(6)
+
+// Checks the block at the index has enough confirmations.
+func (n Confirmations) IsFinal(c Chain, i int) bool {
+	if _, err := c.Get(i); err != nil {
+		return false
+	}
+
+	return c.Length()-1-i >= int(n)
+}
+
+// Checks the block at the index is at or behind a checkpoint matching the chain.
+func (cps Checkpoints) IsFinal(c Chain, i int) bool {
+	if _, err := c.Get(i); err != nil {
+		return false
+	}
+
+	for _, cp := range cps {
+		blk, err := c.Get(cp.Index)
+		if err == nil && cp.Index >= i && bytes.Equal(blk.GetHash(), cp.Hash) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Checks the block with the hash has at least depth blocks built on top of it.
+func (c Chain) IsFinal(hash []byte, depth int) bool {
+	i, err := c.IndexOf(hash)
+	if err != nil {
+		return false
+	}
+
+	return Confirmations(depth).IsFinal(c, i)
+}
+
+// Checks the block with the hash is final using the chain's finality rule.
+func (c Chain) IsFinalized(hash []byte) bool {
+	i, err := c.IndexOf(hash)
+	if err != nil {
+		return false
+	}
+
+	return c.finality().IsFinal(c, i)
+}
+
+// Gets the chain's finality rule, or the default rule if it has none.
+func (c Chain) finality() Finality {
+	if c.Finality == nil {
+		return DefaultFinality
+	}
+
+	return c.Finality
+}
+
+// Checks the blocks from the index onwards can be removed, none of them being final.
+// The block at the index is the oldest to be removed, so it is the first to become final.
+func (c Chain) checkFinal(i int) error {
+	if c.finality().IsFinal(c, i) {
+		return fmt.Errorf("can not remove block at index %d, %w", i, chainerr.ErrFinalBlock)
+	}
+
+	return nil
+}
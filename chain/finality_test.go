@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test finality by confirmation depth.
+func TestIsFinal(t *testing.T) {
+	c := createMinedChain(3)
+	h := c.Blocks[0].GetHash()
+
+	if !c.IsFinal(h, 2) {
+		t.Errorf("expected first block to be final with a depth of 2")
+	}
+
+	if c.IsFinal(h, 3) {
+		t.Errorf("expected first block to not be final with a depth of 3")
+	}
+
+	if c.IsFinal([]byte("missing"), 0) {
+		t.Errorf("expected unknown block to not be final")
+	}
+}
+
+// Test finality using the chain's rule.
+func TestIsFinalized(t *testing.T) {
+	c := createMinedChain(3)
+	h := c.Blocks[1].GetHash()
+
+	// Default rule requires more confirmations.
+	if c.IsFinalized(h) {
+		t.Errorf("expected block to not be final with the default rule")
+	}
+
+	c.Finality = Confirmations(1)
+	if !c.IsFinalized(h) {
+		t.Errorf("expected block to be final with one confirmation")
+	}
+
+	c.Finality = Checkpoints{{Index: 1, Hash: h}}
+	if !c.IsFinalized(c.Blocks[0].GetHash()) || !c.IsFinalized(h) {
+		t.Errorf("expected blocks at or behind the checkpoint to be final")
+	}
+
+	if c.IsFinalized(c.Blocks[2].GetHash()) {
+		t.Errorf("expected block ahead of the checkpoint to not be final")
+	}
+
+	c.Finality = Checkpoints{{Index: 1, Hash: []byte("other")}}
+	if c.IsFinalized(h) {
+		t.Errorf("expected checkpoint not matching the chain to be ignored")
+	}
+}
+
+// Test final blocks can not be removed by a reorg, set head, rollback or invalidation.
+func TestFinalBlockKept(t *testing.T) {
+	c := createMinedChain(4)
+	c.Finality = Confirmations(1)
+	final := c.Blocks[2]
+
+	f2 := createMinedBlock(c.Blocks[1], "Fork")
+	f3 := createMinedBlock(f2, "Fork")
+	f4 := createMinedBlock(f3, "Fork")
+	if err := c.Reorg(true, 2, []*miner.Block{f2, f3, f4}); !errors.Is(err, chainerr.ErrFinalBlock) {
+		t.Errorf("expected final block error but got %v", err)
+	}
+
+	for _, blk := range []*miner.Block{f2, f3, f4} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	if err := c.SetHead(f4.GetHash()); !errors.Is(err, chainerr.ErrFinalBlock) {
+		t.Errorf("expected final block error but got %v", err)
+	}
+
+	if err := c.Rollback(2); !errors.Is(err, chainerr.ErrFinalBlock) {
+		t.Errorf("expected final block error but got %v", err)
+	}
+
+	if err := c.InvalidateBlock(final.GetHash()); !errors.Is(err, chainerr.ErrFinalBlock) {
+		t.Errorf("expected final block error but got %v", err)
+	}
+
+	if c.Length() != 4 || c.Blocks[2] != final || c.IsInvalidated(final.GetHash()) {
+		t.Errorf("expected chain to be untouched")
+	}
+
+	// Blocks which are not final can still be removed.
+	if err := c.Rollback(3); err != nil || c.Length() != 3 {
+		t.Errorf("expected block which is not final to be removed but got %v", err)
+	}
+}
+
+// Create a chain of mined and hashed blocks for testing.
+func createMinedChain(n int) (c *Chain) {
+	c = New()
+
+	var blk *miner.Block
+	for i := 0; i < n; i++ {
+		blk = miner.New(blk, 1, "Block")
+		blk.Mine()
+		blk.GenerateHash(true)
+		c.Append(false, blk)
+	}
+
+	return
+}
@@ -0,0 +1,24 @@
+package chain
+
+import (
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Gets the height of the chain, the index of the head block.
+// Genesis is at a height of 0, an empty chain has a height of -1.
+func (c Chain) Height() int {
+	return c.Length() - 1
+}
+
+// Gets the head block of the chain, the block at the chain's height.
+// If the chain is empty, error is returned.
+func (c Chain) Head() (*miner.Block, error) {
+	return c.GetByHeight(c.Height())
+}
+
+// Gets a block by its height.
+// Blocks are indexed by height, so no blocks are walked to find it.
+// If no block is at the height, error is returned.
+func (c Chain) GetByHeight(h int) (*miner.Block, error) {
+	return c.Get(h)
+}
@@ -0,0 +1,46 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+// Test the height and head of the chain.
+func TestHeight(t *testing.T) {
+	c := New()
+	if h := c.Height(); h != -1 {
+		t.Errorf("expected height of -1 for an empty chain but got %d", h)
+	}
+
+	if _, err := c.Head(); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error for an empty chain but got %v", err)
+	}
+
+	c = createMinedChain(3)
+	if h := c.Height(); h != 2 {
+		t.Errorf("expected height of 2 but got %d", h)
+	}
+
+	if head, err := c.Head(); err != nil || head != c.Blocks[2] {
+		t.Errorf("expected head to be the last block")
+	}
+}
+
+// Test getting blocks by height.
+func TestGetByHeight(t *testing.T) {
+	c := createMinedChain(3)
+
+	for h := 0; h <= c.Height(); h++ {
+		if blk, err := c.GetByHeight(h); err != nil || blk != c.Blocks[h] {
+			t.Errorf("expected block at height %d", h)
+		}
+	}
+
+	for _, h := range []int{-1, 3} {
+		if _, err := c.GetByHeight(h); !errors.Is(err, chainerr.ErrNotFound) {
+			t.Errorf("expected not found error for height %d but got %v", h, err)
+		}
+	}
+}
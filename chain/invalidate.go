@@ -0,0 +1,129 @@
+package chain
+
+import (
+	"bytes"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Marks the block with the hash invalid, along with all of its descendants.
+// If the block is in the chain, the chain is rolled back to its parent and
+// switched to the highest stored branch which is still valid.
+// Used for incident response, such as forcing the chain off a bad branch on a private network.
+// If no block is found, a final block would be removed, or the switch is deeper than the max reorg,
+// nothing is changed and error is returned.
+func (c *Chain) InvalidateBlock(hash []byte) error {
+	blk, h := c.find(hash)
+	if blk == nil {
+		return chainerr.ErrNotFound
+	}
+
+	// Plan the rollback and switch on a copy, so the chain is untouched if either would be refused.
+	tmp := *c
+	tmp.invalid = make(map[string]bool, len(c.invalid)+1)
+	for k := range c.invalid {
+		tmp.invalid[k] = true
+	}
+	tmp.invalid[string(hash)] = true
+
+	canon := c.isCanonical(h, blk)
+	i := c.Length() // Oldest index removed.
+	if canon {
+		tmp.Blocks, i = c.Blocks[:h:h], h
+	}
+
+	if best := tmp.best(); best != nil {
+		j, _, err := tmp.branch(best.blk.GetHash())
+		if err != nil {
+			return err
+		}
+
+		if err := tmp.checkDepth(j); err != nil {
+			return err
+		}
+
+		if j < i {
+			i = j
+		}
+	}
+
+	if err := c.checkFinal(i); err != nil {
+		return err
+	}
+
+	// All good, mark the block and switch.
+	c.invalid = tmp.invalid
+	if canon {
+		if err := c.Rollback(h); err != nil {
+			return err
+		}
+	}
+
+	return c.switchBest()
+}
+
+// Removes the invalid mark from the block with the hash, undoing InvalidateBlock.
+// If the block's branch is now higher than the chain, the chain is switched to it.
+// If no block is found, error is returned.
+func (c *Chain) ReconsiderBlock(hash []byte) error {
+	if blk, _ := c.find(hash); blk == nil {
+		return chainerr.ErrNotFound
+	}
+
+	delete(c.invalid, string(hash))
+
+	return c.switchBest()
+}
+
+// Checks the block with the hash, or one of its ancestors, is marked invalid.
+func (c Chain) IsInvalidated(hash []byte) bool {
+	blk, h := c.find(hash)
+
+	return blk != nil && c.isInvalid(h, blk)
+}
+
+// Checks the block at the height, or one of its ancestors, is marked invalid.
+func (c Chain) isInvalid(h int, blk *miner.Block) bool {
+	if len(c.invalid) == 0 {
+		return false
+	}
+
+	for ; blk != nil; h-- {
+		if c.invalid[string(blk.GetHash())] {
+			return true
+		}
+
+		blk = c.parent(h, blk)
+	}
+
+	return false
+}
+
+// Switches the chain to the highest stored block which is not invalid, if it is higher than the chain.
+func (c *Chain) switchBest() error {
+	best := c.best()
+	if best == nil {
+		return nil
+	}
+
+	return c.SetHead(best.blk.GetHash())
+}
+
+// Gets the highest stored block which is not invalid, if it is higher than the chain.
+// Ties go to the block with the lowest hash, so every node picks the same block.
+func (c Chain) best() (best *stored) {
+	for _, s := range c.store {
+		s := s
+		if s.height <= c.Height() || c.isInvalid(s.height, s.blk) {
+			continue
+		}
+
+		if best == nil || s.height > best.height ||
+			(s.height == best.height && bytes.Compare(s.blk.GetHash(), best.blk.GetHash()) < 0) {
+			best = &s
+		}
+	}
+
+	return
+}
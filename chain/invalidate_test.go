@@ -0,0 +1,111 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test invalidating a block moves the chain to another branch, and reconsidering moves it back.
+func TestInvalidateBlock(t *testing.T) {
+	c := createMinedChain(4)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	s2 := createMinedBlock(old[1], "Side")
+	s3 := createMinedBlock(s2, "Side")
+	for _, blk := range []*miner.Block{s2, s3} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	if err := c.InvalidateBlock(old[2].GetHash()); err != nil {
+		t.Fatalf("expected block to be invalidated but got %s", err)
+	}
+
+	if c.Length() != 4 || c.Blocks[2] != s2 || c.Blocks[3] != s3 {
+		t.Errorf("expected chain to switch to the side branch")
+	}
+
+	if !c.IsInvalidated(old[3].GetHash()) || c.IsInvalidated(s3.GetHash()) {
+		t.Errorf("expected descendants of the block to be invalid")
+	}
+
+	// Descendants can not be stored, or made the head.
+	if err := c.StoreBlock(true, createMinedBlock(old[3], "Bad")); !errors.Is(err, chainerr.ErrInvalidBlock) {
+		t.Errorf("expected invalid block error but got %v", err)
+	}
+
+	if err := c.SetHead(old[3].GetHash()); !errors.Is(err, chainerr.ErrInvalidBlock) {
+		t.Errorf("expected invalid block error but got %v", err)
+	}
+
+	// Reconsidering keeps the chain, the old branch is not higher.
+	if err := c.ReconsiderBlock(old[2].GetHash()); err != nil || c.Blocks[3] != s3 {
+		t.Errorf("expected chain to stay on the side branch")
+	}
+
+	if c.IsInvalidated(old[3].GetHash()) {
+		t.Errorf("expected block to be valid again")
+	}
+
+	// Invalidating the side branch moves back to the old branch.
+	if err := c.InvalidateBlock(s2.GetHash()); err != nil || c.Blocks[2] != old[2] || c.Blocks[3] != old[3] {
+		t.Errorf("expected chain to switch back to the old branch")
+	}
+
+	if err := c.InvalidateBlock([]byte("missing")); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+
+	if err := c.ReconsiderBlock([]byte("missing")); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
+// Test an invalidation which would switch deeper than the max reorg leaves the chain untouched.
+func TestInvalidateBlockMaxReorg(t *testing.T) {
+	c := createMinedChain(4)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	s1 := createMinedBlock(old[0], "Side")
+	s2 := createMinedBlock(s1, "Side")
+	s3 := createMinedBlock(s2, "Side")
+	s4 := createMinedBlock(s3, "Side")
+	for _, blk := range []*miner.Block{s1, s2, s3, s4} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	c.MaxReorg = 1
+	if err := c.InvalidateBlock(old[3].GetHash()); !errors.Is(err, chainerr.ErrReorgTooDeep) {
+		t.Errorf("expected reorg too deep error but got %v", err)
+	}
+
+	if c.Length() != 4 || c.Blocks[3] != old[3] || c.IsInvalidated(old[3].GetHash()) {
+		t.Errorf("expected chain to be untouched")
+	}
+
+	c.MaxReorg = 0
+	if err := c.InvalidateBlock(old[3].GetHash()); err != nil || c.Blocks[1] != s1 || c.Length() != 5 {
+		t.Errorf("expected chain to switch to the side branch but got %v", err)
+	}
+}
+
+// Test invalid blocks can not be appended.
+func TestAppendInvalidatedBlock(t *testing.T) {
+	c := createMinedChain(2)
+	head := c.Blocks[1]
+
+	c.InvalidateBlock(head.GetHash())
+	if c.Length() != 1 {
+		t.Errorf("expected invalid head to be rolled back")
+	}
+
+	if err := c.Append(true, head); !errors.Is(err, chainerr.ErrInvalidBlock) {
+		t.Errorf("expected invalid block error but got %v", err)
+	}
+}
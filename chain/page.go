@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Largest page of blocks which can be requested.
+const MaxPageLimit = 1000
+
+// Gets a page of blocks following the block with the after hash, oldest first.
+// If after is nil, the page starts at the first block. Limit is capped to MaxPageLimit.
+// The cursor for the next page is returned, which will be nil on the last page.
+// If the after block is not found, error is returned.
+func (c Chain) Page(after []byte, limit int) (blks []*miner.Block, next []byte, err error) {
+	i := 0
+	if after != nil {
+		if i, err = c.IndexOf(after); err != nil {
+			return
+		}
+
+		i++
+	}
+
+	if limit <= 0 || limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	end := i + limit
+	if end > c.Length() {
+		end = c.Length()
+	}
+
+	blks = append([]*miner.Block(nil), c.Blocks[i:end]...)
+	if end < c.Length() {
+		next = blks[len(blks)-1].GetHash()
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package chain
+
+import (
+	"testing"
+)
+
+// Test walking the chain a page at a time.
+func TestPage(t *testing.T) {
+	c := createMinedChain(5)
+
+	var after []byte
+	var seen int
+	for pages := 0; ; pages++ {
+		blks, next, err := c.Page(after, 2)
+		if err != nil {
+			t.Fatalf("expected page but got %s", err)
+		}
+
+		for _, blk := range blks {
+			if blk != c.Blocks[seen] {
+				t.Errorf("expected block %d in page order", seen)
+			}
+			seen++
+		}
+
+		if next == nil {
+			if pages != 2 {
+				t.Errorf("expected 3 pages but got %d", pages+1)
+			}
+			break
+		}
+		after = next
+	}
+
+	if seen != 5 {
+		t.Errorf("expected to see 5 blocks but saw %d", seen)
+	}
+}
+
+// Test paging with an unknown cursor.
+func TestPageWithUnknownCursor(t *testing.T) {
+	c := createMinedChain(1)
+	if _, _, err := c.Page([]byte("missing"), 2); err == nil {
+		t.Errorf("expected unknown cursor to return error")
+	}
+
+	// Cursor at the head gives an empty last page.
+	blks, next, err := c.Page(c.Blocks[0].GetHash(), 2)
+	if err != nil || len(blks) != 0 || next != nil {
+		t.Errorf("expected empty last page")
+	}
+}
@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"bytes"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Statistics of stale blocks, blocks seen which are not in the chain.
+// These are side chain blocks which were stored but not made the head, and blocks removed by a rollback or reorg.
+// Blocks which are later added to the chain are no longer stale.
+type StaleStats struct {
+	Blocks int     `json:"blocks"` // Blocks in the chain.
+	Stale  int     `json:"stale"`  // Stale blocks seen.
+	Rate   float64 `json:"rate"`   // Stale blocks out of all blocks seen.
+}
+
+// Gets the stale blocks seen at the index.
+func (c Chain) Stale(i int) []*miner.Block {
+	return c.stale[i]
+}
+
+// Gets the stale block statistics.
+func (c Chain) StaleStats() (st StaleStats) {
+	st.Blocks = c.Length()
+	for _, blks := range c.stale {
+		st.Stale += len(blks)
+	}
+
+	if all := st.Blocks + st.Stale; all > 0 {
+		st.Rate = float64(st.Stale) / float64(all)
+	}
+
+	return
+}
+
+// Removes a block from the stale blocks at the index, once it is back in the chain.
+func (c *Chain) removeStale(i int, blk *miner.Block) {
+	blks, ok := c.stale[i]
+	if !ok {
+		return
+	}
+
+	keep := blks[:0:0]
+	for _, b := range blks {
+		if b != blk && (b.GetHash() == nil || !bytes.Equal(b.GetHash(), blk.GetHash())) {
+			keep = append(keep, b)
+		}
+	}
+
+	if len(keep) == 0 {
+		delete(c.stale, i)
+	} else {
+		c.stale[i] = keep
+	}
+}
+
+// Records a stale block at the index.
+func (c *Chain) addStale(i int, blk *miner.Block) {
+	if c.stale == nil {
+		c.stale = make(map[int][]*miner.Block)
+	}
+
+	c.stale[i] = append(c.stale[i], blk)
+}
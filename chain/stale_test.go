@@ -0,0 +1,108 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test blocks removed by a reorg are tracked as stale.
+func TestStale(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	if st := c.StaleStats(); st.Stale != 0 || st.Rate != 0 {
+		t.Errorf("expected no stale blocks but got %v", st)
+	}
+
+	blk := miner.New(old[0], 1, "Fork")
+	blk.Mine()
+	blk.GenerateHash(true)
+	c.Reorg(true, 1, []*miner.Block{blk})
+
+	if s := c.Stale(1); len(s) != 1 || s[0] != old[1] {
+		t.Errorf("expected replaced block to be stale at index 1")
+	}
+
+	if s := c.Stale(2); len(s) != 1 || s[0] != old[2] {
+		t.Errorf("expected replaced block to be stale at index 2")
+	}
+
+	st := c.StaleStats()
+	if st.Blocks != 2 || st.Stale != 2 || st.Rate != 0.5 {
+		t.Errorf("expected 2 blocks and 2 stale blocks but got %v", st)
+	}
+
+	// Moving back to the old branch makes the fork stale instead.
+	if err := c.SetHead(old[2].GetHash()); err != nil {
+		t.Fatalf("expected old branch to be the head but got %s", err)
+	}
+
+	if s := c.Stale(1); len(s) != 1 || s[0] != blk || len(c.Stale(2)) != 0 {
+		t.Errorf("expected only the fork to be stale")
+	}
+
+	if st := c.StaleStats(); st.Blocks != 3 || st.Stale != 1 {
+		t.Errorf("expected 3 blocks and 1 stale block but got %v", st)
+	}
+}
+
+// Test invalidated blocks which are reconsidered are not left stale.
+func TestStaleReconsider(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	side := createMinedBlock(old[0], "Side")
+	c.StoreBlock(true, side)
+
+	c.InvalidateBlock(old[1].GetHash())
+	c.ReconsiderBlock(old[1].GetHash())
+
+	if c.Blocks[1] != old[1] {
+		t.Fatalf("expected the old branch to be the head again")
+	}
+
+	if st := c.StaleStats(); st.Blocks != 3 || st.Stale != 1 || len(c.Stale(1)) != 1 || c.Stale(1)[0] != side {
+		t.Errorf("expected only the side block to be stale but got %v", st)
+	}
+}
+
+// Test stored side chain blocks are stale until they are made part of the chain.
+func TestStaleStored(t *testing.T) {
+	c := createMinedChain(3)
+
+	s1 := createMinedBlock(c.Blocks[0], "Side")
+	s2 := createMinedBlock(c.Blocks[0], "Other")
+	for _, blk := range []*miner.Block{s1, s2} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	if st := c.StaleStats(); st.Blocks != 3 || st.Stale != 2 || len(c.Stale(1)) != 2 {
+		t.Errorf("expected 3 blocks and 2 stale blocks but got %v", st)
+	}
+
+	// Adopting a side block makes the blocks it replaces stale instead.
+	s3 := createMinedBlock(s1, "Side")
+	s4 := createMinedBlock(s3, "Side")
+	for _, blk := range []*miner.Block{s3, s4} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	if err := c.SetHead(s4.GetHash()); err != nil {
+		t.Fatalf("expected side branch to be the head but got %s", err)
+	}
+
+	if st := c.StaleStats(); st.Blocks != 4 || st.Stale != 3 || len(c.Stale(1)) != 2 || len(c.Stale(2)) != 1 {
+		t.Errorf("expected 4 blocks and 3 stale blocks but got %v", st)
+	}
+
+	for _, blk := range c.Stale(1) {
+		if blk == s1 {
+			t.Errorf("expected adopted block to not be stale")
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Block kept in the store, with its height.
+type stored struct {
+	blk    *miner.Block
+	height int
+}
+
+// Stores a block without adding it to the chain, such as a block of a side chain.
+// The block's parent must be in the store, unless it is a genesis block.
+// Stored blocks can be made part of the chain with SetHead.
+// Will return error if block is invalid and validation was asked for.
+func (c *Chain) StoreBlock(ver bool, blk *miner.Block) error {
+	if blk.Miner == nil {
+		return fmt.Errorf("can not store block, %w", chainerr.ErrInvalidMiner)
+	}
+
+	if blk.GetChainID() != c.ID {
+		return fmt.Errorf("can not store block, %w", chainerr.ErrWrongChain)
+	}
+
+	h := blk.GetHash()
+	if h == nil {
+		return fmt.Errorf("can not store block, %w", chainerr.ErrInvalidHash)
+	}
+
+	if b, _ := c.find(h); b != nil {
+		return fmt.Errorf("can not store block, %w", chainerr.ErrKnownBlock)
+	}
+
+	if c.invalid[string(h)] {
+		return fmt.Errorf("can not store block, %w", chainerr.ErrInvalidBlock)
+	}
+
+	// Genesis blocks start at a height of 0.
+	height := 0
+	if ph := blk.GetParentHash(); ph != nil {
+		prev, i := c.find(ph)
+		if prev == nil {
+			return fmt.Errorf("can not store block, %w", chainerr.ErrOrphanBlock)
+		}
+
+		if c.isInvalid(i, prev) {
+			return fmt.Errorf("can not store block, %w", chainerr.ErrInvalidBlock)
+		}
+
+		if ver {
+			if err := c.ValidateInterval(prev, blk); err != nil {
+				return fmt.Errorf("can not store block, %w", err)
+			}
+		}
+
+		height = i + 1
+	}
+
+	if ver {
+		if err := Validate(blk); err != nil {
+			return fmt.Errorf("can not store block, %w", err)
+		}
+
+		if err := c.ValidateDifficulty(blk); err != nil {
+			return fmt.Errorf("can not store block, %w", err)
+		}
+	}
+
+	// Stored blocks are stale until they are made part of the chain.
+	c.put(blk, height)
+	c.addStale(height, blk)
+
+	return nil
+}
+
+// Gets a block by its hash, from the chain or the store.
+// If no block is found, error is returned.
+func (c Chain) GetByHash(hash []byte) (*miner.Block, error) {
+	if blk, _ := c.find(hash); blk != nil {
+		return blk, nil
+	}
+
+	return nil, chainerr.ErrNotFound
+}
+
+// Makes the stored block with the hash the head of the chain.
+// Blocks are moved between the chain and the store from the common ancestor onwards, like a reorg.
+// Stored blocks were checked when stored, so are not validated again.
+// If the block or any of its ancestors are not found, or are marked invalid, error is returned.
+func (c *Chain) SetHead(hash []byte) error {
+	i, branch, err := c.branch(hash)
+	if err != nil {
+		return err
+	}
+
+	// Already the head.
+	if len(branch) == 0 && i == c.Length() {
+		return nil
+	}
+
+	return c.Reorg(false, i, branch)
+}
+
+// Gets the blocks from the chain up to the stored block with the hash, and the index they replace the chain from.
+// If the block or any of its ancestors are not found, or are marked invalid, error is returned.
+func (c Chain) branch(hash []byte) (int, []*miner.Block, error) {
+	blk, h := c.find(hash)
+	if blk == nil {
+		return 0, nil, chainerr.ErrNotFound
+	}
+
+	if c.isInvalid(h, blk) {
+		return 0, nil, chainerr.ErrInvalidBlock
+	}
+
+	// Walk down until the chain is reached, the blocks passed are the new branch.
+	var branch []*miner.Block
+	for ; h >= 0 && !c.isCanonical(h, blk); h-- {
+		branch = append([]*miner.Block{blk}, branch...)
+
+		if blk = c.parent(h, blk); blk == nil && h > 0 {
+			return 0, nil, chainerr.ErrOrphanBlock
+		}
+	}
+
+	return h + 1, branch, nil
+}
+
+// Checks the block is in the chain at the height.
+func (c Chain) isCanonical(h int, blk *miner.Block) bool {
+	b, err := c.Get(h)
+
+	return err == nil && bytes.Equal(b.GetHash(), blk.GetHash())
+}
+
+// Adds a block to the store at the height.
+func (c *Chain) put(blk *miner.Block, h int) {
+	if blk.GetHash() == nil {
+		return
+	}
+
+	c.putStored(string(blk.GetHash()), stored{blk: blk, height: h})
+}
+
+// Adds a stored block to the store by its hash.
+func (c *Chain) putStored(hash string, s stored) {
+	if c.store == nil {
+		c.store = make(map[string]stored)
+	}
+
+	c.store[hash] = s
+}
+
+// Gets a stored block by its hash, from the store or the store it overlays.
+func (c Chain) lookup(hash []byte) (stored, bool) {
+	if s, ok := c.store[string(hash)]; ok {
+		return s, true
+	}
+
+	s, ok := c.base[string(hash)]
+
+	return s, ok
+}
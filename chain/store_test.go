@@ -0,0 +1,165 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test storing side chain blocks leaves the chain as is.
+func TestStoreBlock(t *testing.T) {
+	c := createMinedChain(3)
+
+	blk := createMinedBlock(c.Blocks[0], "Side")
+	if err := c.StoreBlock(true, blk); err != nil {
+		t.Fatalf("expected block to be stored but got %s", err)
+	}
+
+	if c.Length() != 3 {
+		t.Errorf("expected chain to be untouched")
+	}
+
+	if b, err := c.GetByHash(blk.GetHash()); err != nil || b != blk {
+		t.Errorf("expected stored block to be found by hash")
+	}
+
+	if b, err := c.GetByHash(c.Blocks[2].GetHash()); err != nil || b != c.Blocks[2] {
+		t.Errorf("expected chain block to be found by hash")
+	}
+
+	if _, err := c.IndexOf(blk.GetHash()); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected stored block to not be in the chain")
+	}
+}
+
+// Test storing invalid blocks returns errors.
+func TestStoreBlockErrors(t *testing.T) {
+	c := createMinedChain(2)
+
+	unmined := miner.New(c.Blocks[1], 1, "Unmined")
+	unmined.GenerateHash(true)
+
+	orphan := createMinedBlock(createMinedBlock(nil, "Unknown"), "Orphan")
+
+	for _, tc := range []struct {
+		blk *miner.Block
+		e   error
+	}{
+		{&miner.Block{}, chainerr.ErrInvalidMiner},
+		{miner.New(c.Blocks[1], 1, "Unhashed"), chainerr.ErrInvalidHash},
+		{c.Blocks[1], chainerr.ErrKnownBlock},
+		{orphan, chainerr.ErrOrphanBlock},
+		{unmined, chainerr.ErrInvalidPoW},
+	} {
+		if err := c.StoreBlock(true, tc.blk); !errors.Is(err, tc.e) {
+			t.Errorf("expected %v but got %v", tc.e, err)
+		}
+	}
+}
+
+// Test setting the head to a stored block flips the chain to its branch.
+func TestSetHead(t *testing.T) {
+	c := createMinedChain(3)
+	old := append(c.Blocks[:0:0], c.Blocks...)
+
+	s1 := createMinedBlock(old[0], "Side")
+	s2 := createMinedBlock(s1, "Side")
+	s3 := createMinedBlock(s2, "Side")
+	for _, blk := range []*miner.Block{s1, s2, s3} {
+		if err := c.StoreBlock(true, blk); err != nil {
+			t.Fatalf("expected block to be stored but got %s", err)
+		}
+	}
+
+	ch := c.Subscribe(5)
+
+	if err := c.SetHead(s3.GetHash()); err != nil {
+		t.Fatalf("expected head to be set but got %s", err)
+	}
+	c.Unsubscribe(ch)
+
+	if c.Length() != 4 || c.Blocks[1] != s1 || c.Blocks[3] != s3 {
+		t.Errorf("expected chain to follow the side chain")
+	}
+
+	// Old blocks are removed, then the side chain blocks are added.
+	var removed, added int
+	for e := range ch {
+		if e.Removed {
+			removed++
+		} else {
+			added++
+		}
+	}
+
+	if removed != 2 || added != 3 {
+		t.Errorf("expected 2 removed and 3 added events but got %d and %d", removed, added)
+	}
+
+	// Old blocks are still stored, and can be switched back to.
+	if err := c.SetHead(old[2].GetHash()); err != nil {
+		t.Fatalf("expected head to be set but got %s", err)
+	}
+
+	if c.Length() != 3 || c.Blocks[2] != old[2] {
+		t.Errorf("expected chain to follow the old chain")
+	}
+
+	if i, err := c.IndexOf(old[2].GetHash()); err != nil || i != 2 {
+		t.Errorf("expected old head at index 2")
+	}
+
+	// Setting the head to an ancestor rolls back.
+	if err := c.SetHead(old[1].GetHash()); err != nil || c.Length() != 2 {
+		t.Errorf("expected chain to be rolled back to the block")
+	}
+
+	if err := c.SetHead([]byte("missing")); !errors.Is(err, chainerr.ErrNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
+// Creates a mined and hashed block on the parent.
+func createMinedBlock(parent *miner.Block, data string) *miner.Block {
+	blk := miner.New(parent, 1, data)
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	return blk
+}
+
+// Test blocks of a failed reorg are not left in the store.
+func TestReorgFailedStore(t *testing.T) {
+	c := createMinedChain(3)
+	c.MaxReorg = 1
+
+	f1 := createMinedBlock(c.Blocks[0], "Fork")
+	f2 := createMinedBlock(f1, "Fork")
+	f3 := createMinedBlock(f2, "Fork")
+	if err := c.Reorg(true, 1, []*miner.Block{f1, f2, f3}); !errors.Is(err, chainerr.ErrReorgTooDeep) {
+		t.Fatalf("expected reorg too deep error but got %v", err)
+	}
+
+	// The second block does not follow the first.
+	bad := createMinedBlock(c.Blocks[0], "Bad")
+	c.MaxReorg = 0
+	if err := c.Reorg(true, 1, []*miner.Block{f1, bad}); err == nil {
+		t.Fatalf("expected reorg with an orphan block to fail")
+	}
+
+	for _, blk := range []*miner.Block{f1, f2, f3, bad} {
+		if _, err := c.GetByHash(blk.GetHash()); err == nil {
+			t.Errorf("expected block of a failed reorg to not be found")
+		}
+	}
+
+	if err := c.Reorg(true, 1, []*miner.Block{f1, f2, f3}); err != nil {
+		t.Fatalf("expected reorg to succeed but got %s", err)
+	}
+
+	if _, err := c.GetByHash(f3.GetHash()); err != nil || c.Length() != 4 {
+		t.Errorf("expected blocks of the reorg to be found")
+	}
+}
@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// A stage of the validation pipeline.
+type Stage func(blk *miner.Block) error
+
+// Validation pipeline, ran in order.
+// Cheap header checks come before expensive body checks so bad blocks are rejected early.
+var Pipeline = []Stage{
+	ValidateHeader,
+	ValidateBody,
+}
+
+// Header stage, checks linkage, PoW, and timestamps.
+func ValidateHeader(blk *miner.Block) error {
+	return blk.Miner.ValidateHeader()
+}
+
+// Body stage, checks the hashes.
+func ValidateBody(blk *miner.Block) error {
+	return blk.Miner.ValidateBody()
+}
+
+// Runs the block through the validation pipeline.
+// The first stage to fail will stop the pipeline and its error is returned.
+func Validate(blk *miner.Block) error {
+	if blk == nil || blk.Miner == nil {
+		return chainerr.ErrInvalidMiner
+	}
+
+	for _, st := range Pipeline {
+		if err := st(blk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Filters blocks through the validation pipeline one stage at a time.
+// Each stage only runs against blocks which passed the previous stage, so bad
+// blocks never reach the expensive checks. Valid blocks are returned in order.
+func Filter(blks []*miner.Block) (ok []*miner.Block) {
+	for _, blk := range blks {
+		if blk != nil && blk.Miner != nil {
+			ok = append(ok, blk)
+		}
+	}
+
+	for _, st := range Pipeline {
+		pass := ok[:0]
+		for _, blk := range ok {
+			if st(blk) == nil {
+				pass = append(pass, blk)
+			}
+		}
+
+		ok = pass
+	}
+
+	return
+}
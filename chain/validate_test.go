@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test the pipeline rejects bad headers before checking the body.
+func TestValidateHeaderFirst(t *testing.T) {
+	blk := miner.New(nil, 1, "One")
+	blk.GenerateHash(true) // Hashed but not mined.
+
+	if err := Validate(blk); err == nil || err.Error() != "chunk PoW is not valid" {
+		t.Errorf("expected header check to reject the block but got %v", err)
+	}
+
+	blk.Mine()
+	if err := Validate(blk); err == nil || err.Error() != "chunk hash is not reproducible" {
+		t.Errorf("expected body check to reject the block but got %v", err)
+	}
+
+	blk.GenerateHash(true)
+	if err := Validate(blk); err != nil {
+		t.Errorf("expected block to validate but got %s", err)
+	}
+}
+
+// Test a header with a timestamp before its parent is rejected.
+func TestValidateHeaderTimestamp(t *testing.T) {
+	blk := miner.New(nil, 1, "One")
+	blk2 := miner.New(blk, 1, "Two")
+	ck := (blk.Miner).(*miner.Chunk)
+	ck2 := (blk2.Miner).(*miner.Chunk)
+	ck2.Timestamp = ck.Timestamp - 1
+
+	blk.Mine()
+	blk.GenerateHash(true)
+	blk2.Mine()
+	blk2.GenerateHash(true)
+
+	if err := ValidateHeader(blk2); err == nil {
+		t.Errorf("expected header with an early timestamp to be invalid")
+	}
+}
+
+// Test filtering valid blocks out of a batch.
+func TestFilter(t *testing.T) {
+	blk := miner.New(nil, 1, "One")
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	bad := miner.New(blk, 1, "Bad") // Not mined.
+	blk2 := miner.New(blk, 1, "Two")
+	blk2.Mine()
+	blk2.GenerateHash(true)
+
+	ok := Filter([]*miner.Block{blk, bad, nil, blk2})
+	if len(ok) != 2 || ok[0] != blk || ok[1] != blk2 {
+		t.Errorf("expected only the valid blocks to pass the filter")
+	}
+}
@@ -0,0 +1,51 @@
+package chainerr
+
+import (
+	"errors"
+)
+
+// Errors returned across block creation and validation.
+// Callers can branch on the cause of a failure with errors.Is.
+var (
+	// Block does not exist.
+	ErrNotFound = errors.New("no block found")
+
+	// Block has no miner, or a miner which can not be used.
+	ErrInvalidMiner = errors.New("miner is not valid")
+
+	// PoW does not solve the difficulty.
+	ErrInvalidPoW = errors.New("PoW is not valid")
+
+	// PoW was not found within the attempts allowed.
+	ErrPoWNotFound = errors.New("PoW not found")
+
+	// Hash does not match a regeneration of the hash.
+	ErrInvalidHash = errors.New("hash is not reproducible")
+
+	// Block does not follow its parent, or its parent is not the head of the chain.
+	ErrOrphanBlock = errors.New("block does not follow its parent")
+
+	// Block, or one of its ancestors, was marked invalid.
+	ErrInvalidBlock = errors.New("block is marked invalid")
+
+	// Block is already in the chain.
+	ErrKnownBlock = errors.New("block is already known")
+
+	// Difficulty does not match the difficulty scheduled for the height.
+	ErrBadDifficulty = errors.New("difficulty is not valid")
+
+	// Timestamp is before the parent's, or too soon after it.
+	ErrBadTimestamp = errors.New("timestamp is not valid")
+
+	// Extra data is larger than allowed.
+	ErrExtraData = errors.New("extra data is too long")
+
+	// Block belongs to another chain.
+	ErrWrongChain = errors.New("chain ID does not match")
+
+	// Reorg would remove more blocks than the chain allows.
+	ErrReorgTooDeep = errors.New("reorg is too deep")
+
+	// Block is final, so can not be removed from the chain.
+	ErrFinalBlock = errors.New("block is final")
+)
@@ -0,0 +1,192 @@
+// Package chaingen generates random chains which are reproducible from a seed.
+// Generated chains hold transfers between accounts, scheduled difficulty changes, and reorgs,
+// for use as a corpus when testing storage and sync.
+package chaingen
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/network"
+)
+
+type (
+	// Configuration for generating a chain.
+	// Zero values are replaced by their defaults.
+	Config struct {
+		Seed          int64   // Seed of the random source, the same seed generates the same chain.
+		Blocks        int     // Blocks in the generated chain, including genesis, 16 if not set.
+		Accounts      int     // Accounts transfers are made between, 8 if not set.
+		MaxTransfers  int     // Most transfers in a block, 4 if not set.
+		MaxDifficulty int     // Highest difficulty scheduled, 2 if not set.
+		Upgrades      int     // Difficulty changes scheduled, none if not set.
+		ReorgChance   float64 // Chance of a reorg at each block, from 0 to 1.
+		MaxReorg      int     // Deepest reorg, 3 if not set.
+	}
+
+	// Reprecents a transfer between two accounts, the payload of generated blocks.
+	Transfer struct {
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Amount int    `json:"amount"`
+	}
+
+	// Reprecents a generated chain.
+	Result struct {
+		Network network.Network // Network the chain follows, with the generated upgrades.
+		Chain   *chain.Chain    // Generated chain, with reorged blocks kept in its store.
+		Reorgs  int             // Reorgs performed while generating.
+	}
+
+	// Generator state.
+	gen struct {
+		cfg Config
+		rnd *rand.Rand
+		net network.Network
+		c   *chain.Chain
+	}
+)
+
+// Time of the genesis block of every generated chain.
+var Epoch = time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+
+// Generates a chain from the config.
+// If a block fails to be mined or added, error is returned.
+func Generate(cfg Config) (*Result, error) {
+	cfg = cfg.withDefaults()
+	g := &gen{cfg: cfg, rnd: rand.New(rand.NewSource(cfg.Seed))}
+
+	g.net = network.Network{Name: fmt.Sprintf("gen-%d", cfg.Seed), ID: 1 + g.rnd.Intn(1000), Difficulty: 1}
+	for i, last := 0, 0; i < cfg.Upgrades; i++ {
+		last += 1 + g.rnd.Intn(cfg.Blocks/(cfg.Upgrades+1)+1)
+		g.net.Upgrades = append(g.net.Upgrades, network.Upgrade{Height: last, Difficulty: 1 + g.rnd.Intn(cfg.MaxDifficulty)})
+	}
+	g.c = g.net.Chain()
+
+	r := &Result{Network: g.net, Chain: g.c}
+	for g.c.Length() < cfg.Blocks {
+		// Reorgs grow the chain by one block, so none are made for the last block.
+		if h := g.c.Height(); h > 0 && g.c.Length() < cfg.Blocks-1 && g.rnd.Float64() < cfg.ReorgChance {
+			d := cfg.MaxReorg
+			if d > h {
+				d = h
+			}
+
+			r.Reorgs++
+			if err := g.reorg(1+g.rnd.Intn(d), r.Reorgs); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		head, _ := g.c.Head()
+		blk, err := g.block(head, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := g.c.Append(true, blk); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Replaces the last blocks of the chain with a branch one block longer.
+// Branch blocks are tagged with the reorg number, so they never match the blocks they replace.
+func (g *gen) reorg(depth, n int) error {
+	prev, err := g.c.GetByHeight(g.c.Height() - depth)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i <= depth; i++ {
+		if prev, err = g.block(prev, []byte(fmt.Sprintf("reorg-%d", n))); err != nil {
+			return err
+		}
+
+		if err := g.c.StoreBlock(true, prev); err != nil {
+			return err
+		}
+	}
+
+	return g.c.SetHead(prev.GetHash())
+}
+
+// Mines a block of random transfers on the parent, or a genesis block if the parent is nil.
+func (g *gen) block(parent *miner.Block, extra []byte) (*miner.Block, error) {
+	b := miner.NewBlockBuilder().
+		WithParent(parent).
+		WithPayload(g.payload()).
+		WithExtraData(extra).
+		WithDifficultyFromEngine(g.net)
+
+	if parent == nil {
+		b.WithChainID(g.net.ID).WithTimestamp(Epoch)
+	} else {
+		h := parent.GetIndex() + 1
+		jitter := time.Duration(g.rnd.Intn(60)) * time.Second
+		b.WithTimestamp(parent.GetTimestamp().Add(g.net.MinIntervalAt(h) + time.Second + jitter))
+	}
+
+	blk, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := blk.Mine(); !ok {
+		return nil, fmt.Errorf("can not generate block, %w", chainerr.ErrPoWNotFound)
+	}
+	blk.GenerateHash(true)
+
+	return blk, nil
+}
+
+// Encodes random transfers between the accounts.
+func (g *gen) payload() string {
+	ts := make([]Transfer, g.rnd.Intn(g.cfg.MaxTransfers+1))
+	for i := range ts {
+		ts[i] = Transfer{
+			From:   fmt.Sprintf("acct-%d", g.rnd.Intn(g.cfg.Accounts)),
+			To:     fmt.Sprintf("acct-%d", g.rnd.Intn(g.cfg.Accounts)),
+			Amount: 1 + g.rnd.Intn(100),
+		}
+	}
+
+	j, _ := json.Marshal(ts)
+
+	return string(j)
+}
+
+// Fills the unset fields of the config with their defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.Blocks <= 0 {
+		cfg.Blocks = 16
+	}
+
+	if cfg.Accounts <= 0 {
+		cfg.Accounts = 8
+	}
+
+	if cfg.MaxTransfers <= 0 {
+		cfg.MaxTransfers = 4
+	}
+
+	if cfg.MaxDifficulty <= 0 {
+		cfg.MaxDifficulty = 2
+	}
+
+	if cfg.MaxReorg <= 0 {
+		cfg.MaxReorg = 3
+	}
+
+	return cfg
+}
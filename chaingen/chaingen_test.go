@@ -0,0 +1,66 @@
+package chaingen
+
+import (
+	"bytes"
+	"testing"
+
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test the same seed generates the same chain.
+func TestGenerateReproducible(t *testing.T) {
+	cfg := Config{Seed: 42, Blocks: 24, Upgrades: 2, ReorgChance: 0.3}
+
+	a, err := Generate(cfg)
+	if err != nil {
+		t.Fatalf("expected chain to generate but got %s", err)
+	}
+
+	b, _ := Generate(cfg)
+	if !bytes.Equal(a.Chain.Encode(), b.Chain.Encode()) || a.Reorgs != b.Reorgs {
+		t.Errorf("expected the same seed to generate the same chain")
+	}
+
+	cfg.Seed = 43
+	c, _ := Generate(cfg)
+	if bytes.Equal(a.Chain.Encode(), c.Chain.Encode()) {
+		t.Errorf("expected another seed to generate another chain")
+	}
+}
+
+// Test generated chains are valid for their network.
+func TestGenerateValid(t *testing.T) {
+	r, err := Generate(Config{Seed: 7, Blocks: 32, Upgrades: 3, ReorgChance: 0.25})
+	if err != nil {
+		t.Fatalf("expected chain to generate but got %s", err)
+	}
+
+	if r.Chain.Length() != 32 {
+		t.Errorf("expected 32 blocks but got %d", r.Chain.Length())
+	}
+
+	if !r.Chain.IsValid() || r.Network.Validate() != nil {
+		t.Errorf("expected generated chain and network to be valid")
+	}
+
+	if r.Reorgs == 0 || len(r.Network.Upgrades) != 3 {
+		t.Errorf("expected reorgs and upgrades but got %d and %d", r.Reorgs, len(r.Network.Upgrades))
+	}
+
+	if r.Chain.StaleStats().Stale == 0 {
+		t.Errorf("expected reorged blocks to be stale")
+	}
+
+	for _, blk := range r.Chain.Blocks {
+		if r.Chain.ValidateDifficulty(blk) != nil {
+			t.Errorf("expected block %d to follow the network's difficulty", blk.GetIndex())
+		}
+
+		var ts []Transfer
+		if err := json.Unmarshal([]byte(blk.Miner.(*miner.Chunk).Data), &ts); err != nil {
+			t.Errorf("expected block %d to hold transfers but got %s", blk.GetIndex(), err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Source of the current time, and timers which fire by it.
+	// Injected where the time is read or waited on, so simulations and tests can control it.
+	Clock interface {
+		Now() time.Time
+		NewTimer(d time.Duration) Timer
+	}
+
+	// Timer which sends the time on its channel once its duration has passed on its clock.
+	Timer interface {
+		C() <-chan time.Time
+		Stop() bool // Stops the timer, returns false if it already fired or was stopped.
+	}
+)
+
+// Clock which reads the system time.
+var System Clock = system{}
+
+// Reads the system time.
+type system struct{}
+
+// Gets the system time.
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+// Creates a timer which fires after the duration of system time.
+func (system) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// Timer of the system clock.
+type systemTimer struct {
+	t *time.Timer
+}
+
+// Gets the channel the time is sent on.
+func (st systemTimer) C() <-chan time.Time {
+	return st.t.C
+}
+
+// Stops the timer.
+func (st systemTimer) Stop() bool {
+	return st.t.Stop()
+}
+
+// Clock which only moves when told to.
+// Timers fire once the clock is moved to or past their deadline.
+// Safe for use from multiple goroutines.
+type Manual struct {
+	mu     sync.Mutex
+	t      time.Time
+	timers map[*manualTimer]bool // Timers which have not fired or been stopped.
+}
+
+// Timer of a manual clock.
+type manualTimer struct {
+	m  *Manual
+	at time.Time
+	c  chan time.Time
+}
+
+// Creates a new manual clock set to the time.
+func NewManual(t time.Time) *Manual {
+	return &Manual{t: t}
+}
+
+// Gets the time of the clock.
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.t
+}
+
+// Creates a timer which fires once the clock is moved the duration forward.
+// Timers with no duration fire straight away.
+func (m *Manual) NewTimer(d time.Duration) Timer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mt := &manualTimer{m: m, at: m.t.Add(d), c: make(chan time.Time, 1)}
+	if m.timers == nil {
+		m.timers = make(map[*manualTimer]bool)
+	}
+	m.timers[mt] = true
+	m.fire()
+
+	return mt
+}
+
+// Sets the time of the clock.
+func (m *Manual) Set(t time.Time) {
+	m.mu.Lock()
+	m.t = t
+	m.fire()
+	m.mu.Unlock()
+}
+
+// Moves the clock forward by the duration.
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	m.t = m.t.Add(d)
+	m.fire()
+	m.mu.Unlock()
+}
+
+// Fires the timers whose deadline has been reached, must be called with the lock held.
+func (m *Manual) fire() {
+	for mt := range m.timers {
+		if !mt.at.After(m.t) {
+			mt.c <- m.t
+			delete(m.timers, mt)
+		}
+	}
+}
+
+// Gets the channel the time is sent on.
+func (mt *manualTimer) C() <-chan time.Time {
+	return mt.c
+}
+
+// Stops the timer.
+func (mt *manualTimer) Stop() bool {
+	mt.m.mu.Lock()
+	defer mt.m.mu.Unlock()
+
+	ok := mt.m.timers[mt]
+	delete(mt.m.timers, mt)
+
+	return ok
+}
@@ -0,0 +1,79 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// Test the system clock reads the system time.
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	now := System.Now()
+
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("expected system time but got %s", now)
+	}
+}
+
+// Test the manual clock only moves when told to.
+func TestManual(t *testing.T) {
+	ts := time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+	m := NewManual(ts)
+
+	if !m.Now().Equal(ts) {
+		t.Errorf("expected clock to be at %s but got %s", ts, m.Now())
+	}
+
+	m.Advance(time.Minute)
+	if e := ts.Add(time.Minute); !m.Now().Equal(e) {
+		t.Errorf("expected clock to be at %s but got %s", e, m.Now())
+	}
+
+	m.Set(ts)
+	if !m.Now().Equal(ts) {
+		t.Errorf("expected clock to be at %s but got %s", ts, m.Now())
+	}
+}
+
+// Test manual timers only fire once the clock reaches their deadline.
+func TestManualTimer(t *testing.T) {
+	m := NewManual(time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC))
+	tm := m.NewTimer(time.Hour)
+
+	m.Advance(time.Minute)
+	select {
+	case <-tm.C():
+		t.Errorf("expected timer to not fire before its deadline")
+	default:
+	}
+
+	m.Advance(2 * time.Hour)
+	select {
+	case <-tm.C():
+	default:
+		t.Errorf("expected timer to fire after its deadline")
+	}
+
+	if tm.Stop() {
+		t.Errorf("expected fired timer to not stop")
+	}
+
+	if tm2 := m.NewTimer(time.Minute); !tm2.Stop() {
+		t.Errorf("expected pending timer to stop")
+	}
+
+	select {
+	case <-m.NewTimer(0).C():
+	default:
+		t.Errorf("expected timer with no duration to fire straight away")
+	}
+}
+
+// Test system timers fire after their duration.
+func TestSystemTimer(t *testing.T) {
+	select {
+	case <-System.NewTimer(time.Millisecond).C():
+	case <-time.After(time.Second):
+		t.Errorf("expected system timer to fire")
+	}
+}
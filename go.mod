@@ -1 +1,3 @@
 module github.com/ohmybrew/gochain
+
+go 1.18
@@ -0,0 +1,154 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chain"
+)
+
+type (
+	// Reprecents a block as written to a sink.
+	// Removed is set when the block was undone by a rollback or reorg, sinks should delete it.
+	Record struct {
+		Index      int    `json:"index"`
+		Hash       []byte `json:"hash"`
+		ParentHash []byte `json:"parent_hash"`
+		ChainID    int    `json:"chain_id,omitempty"`
+		Timestamp  int64  `json:"timestamp"` // Unix milliseconds.
+		Encoded    []byte `json:"block"`     // Block encoded by its miner.
+		Removed    bool   `json:"removed"`
+	}
+
+	// Destination for records, such as a database or message queue.
+	Sink interface {
+		Write(r Record) error
+	}
+
+	// Function which can be used as a sink.
+	SinkFunc func(r Record) error
+
+	// Follows a chain and writes a record for every change to the sinks, in order.
+	Indexer struct {
+		Chain  *chain.Chain
+		Sinks  []Sink
+		Buffer int // Events buffered while the sinks are writing.
+	}
+)
+
+// Writes the record with the function.
+func (f SinkFunc) Write(r Record) error {
+	return f(r)
+}
+
+// Creates a new indexer for the chain, writing to the sinks.
+func New(c *chain.Chain, sinks ...Sink) *Indexer {
+	return &Indexer{Chain: c, Sinks: sinks}
+}
+
+// Runs the indexer until the context is done, or a sink fails.
+// Only changes made after Run is called are written, blocks already in the chain are not.
+// On a rollback or reorg, removed records for the undone blocks are written before records for the new blocks.
+// Returns the error of the sink which failed, or nil once the context is done.
+func (ix *Indexer) Run(ctx context.Context) error {
+	return <-ix.Start(ctx)
+}
+
+// Starts the indexer in the background, see Run.
+// Changes made once Start returns are written. The result of the run is sent on the channel.
+func (ix *Indexer) Start(ctx context.Context) <-chan error {
+	ch := ix.Chain.Subscribe(ix.Buffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- ix.follow(ctx, ch)
+	}()
+
+	return errc
+}
+
+// Writes records for the events until the context is done, or a sink fails.
+func (ix *Indexer) follow(ctx context.Context, ch <-chan chain.Event) error {
+	defer func() {
+		// Keep receiving so a pending event can not block unsubscribing.
+		go func() {
+			for range ch {
+			}
+		}()
+
+		ix.Chain.Unsubscribe(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-ch:
+			r := Record{
+				Index:      e.Index,
+				Hash:       e.Block.GetHash(),
+				ParentHash: e.Block.GetParentHash(),
+				ChainID:    e.Block.GetChainID(),
+				Timestamp:  e.Block.GetTimestamp().UnixMilli(),
+				Encoded:    e.Block.Encode(),
+				Removed:    e.Removed,
+			}
+
+			for _, s := range ix.Sinks {
+				if err := s.Write(r); err != nil {
+					return fmt.Errorf("can not write block %d, %w", r.Index, err)
+				}
+			}
+		}
+	}
+}
+
+// Sink which keeps the current records in memory, by index.
+// Safe for use from multiple goroutines.
+type Memory struct {
+	mu   sync.Mutex
+	recs []Record
+}
+
+// Writes the record, removing it and every record after it if removed.
+func (m *Memory) Write(r Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.Removed {
+		if r.Index < len(m.recs) {
+			m.recs = m.recs[:r.Index]
+		}
+
+		return nil
+	}
+
+	if r.Index != len(m.recs) {
+		return fmt.Errorf("expected record %d but got %d", len(m.recs), r.Index)
+	}
+
+	m.recs = append(m.recs, r)
+
+	return nil
+}
+
+// Gets a copy of the current records, in chain order.
+func (m *Memory) Records() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Record(nil), m.recs...)
+}
+
+// Creates a sink which writes each record as a line of JSON, for piping to other tools.
+func JSONSink(w io.Writer) Sink {
+	enc := json.NewEncoder(w)
+
+	return SinkFunc(func(r Record) error {
+		return enc.Encode(r)
+	})
+}
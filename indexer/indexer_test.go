@@ -0,0 +1,92 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test the indexer follows appends and reorgs.
+func TestIndexer(t *testing.T) {
+	c := chain.New()
+	m := new(Memory)
+	var buf bytes.Buffer
+
+	seen := make(chan struct{}, 8)
+	count := SinkFunc(func(r Record) error {
+		seen <- struct{}{}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := New(c, m, JSONSink(&buf), count).Start(ctx)
+
+	blks := createBlocks(nil, 3, "Block")
+	for _, blk := range blks {
+		c.Append(true, blk)
+	}
+
+	fork := createBlocks(blks[0], 3, "Fork")
+	if err := c.Reorg(true, 1, fork); err != nil {
+		t.Fatalf("expected reorg but got %s", err)
+	}
+
+	// 3 appended, 2 removed, 3 appended.
+	for i := 0; i < 8; i++ {
+		<-seen
+	}
+	cancel()
+
+	if err := <-errc; err != nil {
+		t.Errorf("expected indexer to stop cleanly but got %s", err)
+	}
+
+	recs := m.Records()
+	if len(recs) != 4 || !bytes.Equal(recs[0].Hash, blks[0].GetHash()) || !bytes.Equal(recs[3].Hash, fork[2].GetHash()) {
+		t.Errorf("expected records to follow the reorged chain but got %d records", len(recs))
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 8 || strings.Count(buf.String(), `"removed":true`) != 2 {
+		t.Errorf("expected 8 JSON lines with 2 removals but got %d lines", lines)
+	}
+}
+
+// Test the indexer stops when a sink fails.
+func TestIndexerSinkError(t *testing.T) {
+	c := chain.New()
+	fail := SinkFunc(func(r Record) error { return errors.New("sink is down") })
+	errc := New(c, fail).Start(context.Background())
+
+	c.Append(false, createBlocks(nil, 1, "Block")[0])
+	if err := <-errc; err == nil {
+		t.Errorf("expected sink error")
+	}
+
+	// Indexer unsubscribed, so the chain does not block.
+	c.Append(false, createBlocks(nil, 1, "Other")[0])
+}
+
+// Test the memory sink rejects records out of order.
+func TestMemoryOutOfOrder(t *testing.T) {
+	m := new(Memory)
+	if err := m.Write(Record{Index: 1}); err == nil {
+		t.Errorf("expected error for a record out of order")
+	}
+}
+
+// Creates mined blocks following the parent.
+func createBlocks(parent *miner.Block, n int, data string) (blks []*miner.Block) {
+	for i := 0; i < n; i++ {
+		parent = miner.New(parent, 1, data)
+		parent.Mine()
+		parent.GenerateHash(true)
+		blks = append(blks, parent)
+	}
+
+	return
+}
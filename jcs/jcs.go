@@ -0,0 +1,195 @@
+package jcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"encoding/json"
+)
+
+// Transforms JSON to its canonical form as per the JSON Canonicalization Scheme (RFC 8785).
+// Object keys are sorted, whitespace is removed, and strings and numbers are
+// serialized the same way every time so the output is safe to hash or sign.
+func Transform(j []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	// Only a single value is allowed.
+	if d.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	var b bytes.Buffer
+	if err := write(&b, v); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Writes a decoded value in canonical form.
+func write(b *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(t))
+	case string:
+		writeString(b, t)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(t), 64)
+		if err != nil {
+			return err
+		}
+
+		n, err := FormatNumber(f)
+		if err != nil {
+			return err
+		}
+
+		b.WriteString(n)
+	case []interface{}:
+		b.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			if err := write(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case map[string]interface{}:
+		// Keys are sorted by their UTF-16 code units.
+		ks := make([]string, 0, len(t))
+		for k := range t {
+			ks = append(ks, k)
+		}
+		sort.Slice(ks, func(i, j int) bool {
+			return lessUTF16(ks[i], ks[j])
+		})
+
+		b.WriteByte('{')
+		for i, k := range ks {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			writeString(b, k)
+			b.WriteByte(':')
+			if err := write(b, t[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported JSON value %T", v)
+	}
+
+	return nil
+}
+
+// Writes a string, only escaping what JSON requires.
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// Compares two strings by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+
+	return len(ua) < len(ub)
+}
+
+// Formats a number the same as ECMAScript's Number.prototype.toString, as required by JCS.
+// NaN and infinity can not be represented in JSON, so error is returned.
+func FormatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("number can not be represented in JSON")
+	}
+
+	// Covers negative zero too.
+	if f == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+
+	// Shortest digits which round trip, as "d.ddde±x".
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mant, exp, _ := strings.Cut(e, "e")
+	digits := strings.Replace(mant, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+
+	// The value is 0.digits * 10^n.
+	k, n := len(digits), x+1
+
+	var s string
+	switch {
+	case k <= n && n <= 21:
+		s = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		s = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		s = "0." + strings.Repeat("0", -n) + digits
+	default:
+		es := "+"
+		if n-1 < 0 {
+			es = "-"
+		}
+
+		s = digits[:1]
+		if k > 1 {
+			s += "." + digits[1:]
+		}
+
+		s += "e" + es + strconv.Itoa(int(math.Abs(float64(n-1))))
+	}
+
+	return sign + s, nil
+}
@@ -0,0 +1,147 @@
+package miner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+type (
+	// Provides the difficulty of the block to follow the parent.
+	// Parent will be nil for a genesis block.
+	DifficultyEngine interface {
+		NextDifficulty(parent Miner) int
+	}
+
+	// Difficulty engine which always gives the same difficulty.
+	FixedDifficulty int
+
+	// Builds unsealed blocks, ready to be mined.
+	BlockBuilder struct {
+		parent  *Block
+		data    string
+		ts      time.Time
+		dif     int
+		engine  DifficultyEngine
+		factory Factory
+		chainID *int
+		extra   []byte
+	}
+)
+
+// Gives the fixed difficulty.
+func (d FixedDifficulty) NextDifficulty(parent Miner) int {
+	return int(d)
+}
+
+// Creates a new block builder.
+// Without options, a genesis chunk with no data and a difficulty of 1 is built.
+func NewBlockBuilder() *BlockBuilder {
+	return &BlockBuilder{dif: 1}
+}
+
+// Builds the block on top of the parent block.
+func (b *BlockBuilder) WithParent(blk *Block) *BlockBuilder {
+	b.parent = blk
+
+	return b
+}
+
+// Sets the data of the block.
+func (b *BlockBuilder) WithPayload(data string) *BlockBuilder {
+	b.data = data
+
+	return b
+}
+
+// Sets the timestamp of the block, instead of the time it is built.
+func (b *BlockBuilder) WithTimestamp(ts time.Time) *BlockBuilder {
+	b.ts = ts
+
+	return b
+}
+
+// Sets a fixed difficulty for the block.
+func (b *BlockBuilder) WithDifficulty(dif int) *BlockBuilder {
+	b.dif, b.engine = dif, nil
+
+	return b
+}
+
+// Sets the difficulty of the block from the engine, based on the parent.
+func (b *BlockBuilder) WithDifficultyFromEngine(e DifficultyEngine) *BlockBuilder {
+	b.engine = e
+
+	return b
+}
+
+// Sets the factory which creates the block's miner, NewChunk is used if not set.
+func (b *BlockBuilder) WithFactory(f Factory) *BlockBuilder {
+	b.factory = f
+
+	return b
+}
+
+// Sets the chain ID of the block, instead of inheriting it from the parent.
+func (b *BlockBuilder) WithChainID(id int) *BlockBuilder {
+	b.chainID = &id
+
+	return b
+}
+
+// Sets the extra data of the block, such as a pool tag or version string.
+// Extra data beyond MaxExtraData bytes errors on build.
+func (b *BlockBuilder) WithExtraData(x []byte) *BlockBuilder {
+	b.extra = x
+
+	return b
+}
+
+// Builds the unsealed block.
+// Timestamps, chain IDs, and extra data can only be set on chunks, error is returned for other miners.
+func (b *BlockBuilder) Build() (*Block, error) {
+	f := b.factory
+	if f == nil {
+		f = NewChunk
+	}
+
+	dif := b.dif
+	if b.engine != nil {
+		var pm Miner
+		if b.parent != nil {
+			pm = b.parent.Miner
+		}
+
+		dif = b.engine.NextDifficulty(pm)
+	}
+
+	blk, err := NewWith(b.parent, f, dif, b.data)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.ts.IsZero() && b.chainID == nil && b.extra == nil {
+		return blk, nil
+	}
+
+	ck, ok := blk.Miner.(*Chunk)
+	if !ok {
+		return nil, fmt.Errorf("can not set timestamp, chain ID, or extra data, %w", chainerr.ErrInvalidMiner)
+	}
+
+	if len(b.extra) > MaxExtraData {
+		return nil, fmt.Errorf("can not set extra data, %w", chainerr.ErrExtraData)
+	}
+	ck.ExtraData = b.extra
+
+	if !b.ts.IsZero() {
+		ck.Timestamp = b.ts.UnixMilli()
+	}
+
+	if b.chainID != nil {
+		ck.ChainID = *b.chainID
+	}
+
+	return blk, nil
+}
@@ -0,0 +1,88 @@
+package miner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+// Engine which increases the difficulty with every block.
+type stepEngine struct{}
+
+func (stepEngine) NextDifficulty(parent Miner) int {
+	if parent == nil {
+		return 1
+	}
+
+	return parent.(*Chunk).Difficulty + 1
+}
+
+// Test building a chain of blocks with options.
+func TestBlockBuilder(t *testing.T) {
+	ts := time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+	blk, err := NewBlockBuilder().
+		WithPayload("One").
+		WithTimestamp(ts).
+		WithChainID(3).
+		WithDifficultyFromEngine(stepEngine{}).
+		Build()
+	if err != nil {
+		t.Fatalf("expected block to build but got %s", err)
+	}
+
+	ck := getChunk(blk)
+	if ck.Data != "One" || ck.Timestamp != ts.UnixMilli() || ck.ChainID != 3 || ck.Difficulty != 1 || ck.IsMined() {
+		t.Errorf("expected unsealed block with the options but got %+v", ck)
+	}
+
+	blk2, _ := NewBlockBuilder().WithParent(blk).WithDifficultyFromEngine(stepEngine{}).Build()
+	ck2 := getChunk(blk2)
+	if ck2.Parent != ck || ck2.Difficulty != 2 || ck2.ChainID != 3 {
+		t.Errorf("expected block to follow its parent but got %+v", ck2)
+	}
+
+	blk3, _ := NewBlockBuilder().WithParent(blk2).WithDifficulty(4).Build()
+	if getChunk(blk3).Difficulty != 4 {
+		t.Errorf("expected fixed difficulty of 4")
+	}
+}
+
+// Test extra data is set on the chunk and bounded.
+func TestBlockBuilderWithExtraData(t *testing.T) {
+	blk, err := NewBlockBuilder().WithPayload("One").WithExtraData([]byte("pool")).Build()
+	if err != nil {
+		t.Fatalf("expected block to build but got %s", err)
+	}
+
+	if string(getChunk(blk).ExtraData) != "pool" {
+		t.Errorf("expected extra data to be set but got %q", getChunk(blk).ExtraData)
+	}
+
+	_, err = NewBlockBuilder().WithExtraData(make([]byte, MaxExtraData+1)).Build()
+	if !errors.Is(err, chainerr.ErrExtraData) {
+		t.Errorf("expected extra data error but got %v", err)
+	}
+
+	// Chunks with too much extra data fail validation.
+	blk.Mine()
+	blk.GenerateHash(true)
+	getChunk(blk).ExtraData = make([]byte, MaxExtraData+1)
+	if err := blk.ValidateHeader(); !errors.Is(err, chainerr.ErrExtraData) {
+		t.Errorf("expected extra data error but got %v", err)
+	}
+}
+
+// Test timestamps can not be set on custom miners.
+func TestBlockBuilderWithFactory(t *testing.T) {
+	b := NewBlockBuilder().WithFactory(newCustomMiner)
+	if _, err := b.Build(); err != nil {
+		t.Errorf("expected custom block to build but got %s", err)
+	}
+
+	_, err := b.WithTimestamp(time.Now()).Build()
+	if !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package miner
+
+import (
+	"time"
+)
+
+// Creates a factory for development chunks.
+// Chunks have no difficulty so they seal instantly without PoW, the difficulty passed to the factory is ignored.
+// If the timestamp is not zero, every chunk will use it so hashes are reproducible between runs.
+func DevFactory(ts time.Time) Factory {
+	return func(parent Miner, _ int, data string) (Miner, error) {
+		m, err := NewChunk(parent, 0, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ts.IsZero() {
+			m.(*Chunk).Timestamp = ts.UnixMilli()
+		}
+
+		return m, nil
+	}
+}
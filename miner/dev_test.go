@@ -0,0 +1,46 @@
+package miner
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Test development chunks seal instantly and validate.
+func TestDevFactory(t *testing.T) {
+	f := DevFactory(time.Time{})
+	blk, _ := NewWith(nil, f, 5, "One")
+	blk2, _ := NewWith(blk, f, 5, "Two")
+
+	for _, b := range []*Block{blk, blk2} {
+		if pow, ok := b.Mine(); !ok || pow != 0 {
+			t.Errorf("expected development chunk to seal with a PoW of 0 but got %d", pow)
+		}
+		b.GenerateHash(true)
+	}
+
+	if !blk2.IsMined() || !blk2.IsValid() {
+		t.Errorf("expected development chunk to validate but failed")
+	}
+}
+
+// Test development chunks with a fixed timestamp hash the same between runs.
+func TestDevFactoryFixedTimestamp(t *testing.T) {
+	ts := time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+
+	build := func() []byte {
+		f := DevFactory(ts)
+		blk, _ := NewWith(nil, f, 0, "One")
+		blk2, _ := NewWith(blk, f, 0, "Two")
+		for _, b := range []*Block{blk, blk2} {
+			b.Mine()
+			b.GenerateHash(true)
+		}
+
+		return blk2.GetHash()
+	}
+
+	if a, e := build(), build(); !bytes.Equal(a, e) {
+		t.Errorf("expected hashes to be reproducible but got %x and %x", a, e)
+	}
+}
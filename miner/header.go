@@ -0,0 +1,90 @@
+package miner
+
+import (
+	"bytes"
+	"errors"
+
+	"encoding/binary"
+)
+
+const (
+	// Size of the fixed width part of a chunk's header preimage.
+	HeaderSize = 32 + 8 + 8 + 8 + 8 + 8 + 8 + 4 + 4
+
+	// Largest extra data a chunk can hold.
+	MaxExtraData = 32
+)
+
+// Encodes the chunk's header as the preimage for its hash.
+// Integers are fixed width and big-endian, so the format is unambiguous and can be
+// reproduced by other implementations:
+//
+//	parent_hash  32 bytes, zeros for a genesis chunk
+//	index        uint64
+//	pow          uint64
+//	extra_nonce  uint64
+//	difficulty   uint64
+//	timestamp    int64, Unix milliseconds
+//	chain_id     uint64
+//	extra_length uint32
+//	data_length  uint32
+//	extra_data   extra_length bytes
+//	data         data_length bytes
+func (ck Chunk) EncodeHeader() []byte {
+	return ck.appendHeader(make([]byte, 0, HeaderSize+len(ck.ExtraData)+len(ck.Data)))
+}
+
+// Appends the chunk's header to the buffer, so buffers can be reused between headers.
+func (ck Chunk) appendHeader(buf []byte) []byte {
+	var b [HeaderSize]byte
+
+	copy(b[0:32], ck.GetParent().Hash)
+	binary.BigEndian.PutUint64(b[32:], uint64(ck.Index))
+	binary.BigEndian.PutUint64(b[40:], ck.PoW)
+	binary.BigEndian.PutUint64(b[48:], ck.ExtraNonce)
+	binary.BigEndian.PutUint64(b[56:], uint64(ck.Difficulty))
+	binary.BigEndian.PutUint64(b[64:], uint64(ck.Timestamp))
+	binary.BigEndian.PutUint64(b[72:], uint64(ck.ChainID))
+	binary.BigEndian.PutUint32(b[80:], uint32(len(ck.ExtraData)))
+	binary.BigEndian.PutUint32(b[84:], uint32(len(ck.Data)))
+
+	buf = append(buf, b[:]...)
+	buf = append(buf, ck.ExtraData...)
+
+	return append(buf, ck.Data...)
+}
+
+// Decodes a header made by EncodeHeader into a chunk, and generates its hash.
+// Only the hash of the parent is known, so the parent is a chunk with just its hash, or nil for a genesis chunk.
+// If the header is malformed, error is returned.
+func DecodeHeader(b []byte) (*Chunk, error) {
+	if len(b) < HeaderSize {
+		return nil, errors.New("header is not valid")
+	}
+
+	el, dl := int(binary.BigEndian.Uint32(b[80:])), int(binary.BigEndian.Uint32(b[84:]))
+	if el > MaxExtraData || len(b)-HeaderSize != el+dl {
+		return nil, errors.New("header is not valid")
+	}
+
+	ck := &Chunk{
+		Index:      int(binary.BigEndian.Uint64(b[32:])),
+		PoW:        binary.BigEndian.Uint64(b[40:]),
+		ExtraNonce: binary.BigEndian.Uint64(b[48:]),
+		Difficulty: int(binary.BigEndian.Uint64(b[56:])),
+		Timestamp:  int64(binary.BigEndian.Uint64(b[64:])),
+		ChainID:    int(binary.BigEndian.Uint64(b[72:])),
+		Data:       string(b[HeaderSize+el:]),
+	}
+
+	if el > 0 {
+		ck.ExtraData = append([]byte(nil), b[HeaderSize:HeaderSize+el]...)
+	}
+
+	if ph := b[0:32]; !bytes.Equal(ph, make([]byte, 32)) {
+		ck.Parent = &Chunk{Hash: append([]byte(nil), ph...)}
+	}
+	ck.GenerateHash(true)
+
+	return ck, nil
+}
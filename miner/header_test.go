@@ -0,0 +1,97 @@
+package miner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// Test the header is encoded with fixed width big-endian fields.
+func TestEncodeHeader(t *testing.T) {
+	blk := createBlock()
+	ck := getChunk(blk)
+	ck.PoW = 16
+	ck.ExtraNonce = 7
+	ck.ChainID = 2
+	ck.ExtraData = []byte("pool")
+
+	b := ck.EncodeHeader()
+	if l := HeaderSize + len(ck.ExtraData) + len(ck.Data); len(b) != l {
+		t.Fatalf("expected header of %d bytes but got %d", l, len(b))
+	}
+
+	if !bytes.Equal(b[0:32], make([]byte, 32)) {
+		t.Errorf("expected zero parent hash for genesis chunk")
+	}
+
+	for _, f := range []struct {
+		off int
+		e   uint64
+	}{
+		{32, 0},
+		{40, 16},
+		{48, 7},
+		{56, 1},
+		{64, uint64(ck.Timestamp)},
+		{72, 2},
+	} {
+		if a := binary.BigEndian.Uint64(b[f.off:]); a != f.e {
+			t.Errorf("expected %d at offset %d but got %d", f.e, f.off, a)
+		}
+	}
+
+	if l := binary.BigEndian.Uint32(b[80:]); l != 4 || string(b[HeaderSize:HeaderSize+4]) != "pool" {
+		t.Errorf("expected extra data to follow the lengths")
+	}
+
+	if l := binary.BigEndian.Uint32(b[84:]); l != uint32(len(ck.Data)) || string(b[HeaderSize+4:]) != ck.Data {
+		t.Errorf("expected data to follow the extra data")
+	}
+
+	// Child includes the parent hash.
+	ck.GenerateHash(true)
+	blk2 := New(blk, 1, "Two")
+	if !bytes.Equal(getChunk(blk2).EncodeHeader()[0:32], ck.Hash) {
+		t.Errorf("expected parent hash in child header")
+	}
+}
+
+// Test a header decodes back into the chunk.
+func TestDecodeHeader(t *testing.T) {
+	blk := createBlock()
+	blk.Mine()
+	blk.GenerateHash(true)
+	blk2 := New(blk, 1, "Two")
+	getChunk(blk2).ExtraData = []byte("pool")
+	blk2.Mine()
+	blk2.GenerateHash(true)
+
+	for _, b := range []*Block{blk, blk2} {
+		ck := getChunk(b)
+		dck, err := DecodeHeader(ck.EncodeHeader())
+		if err != nil {
+			t.Fatalf("expected header to decode but got %s", err)
+		}
+
+		if !bytes.Equal(dck.Hash, ck.Hash) || !bytes.Equal(dck.GetParentHash(), ck.GetParentHash()) {
+			t.Errorf("expected decoded chunk to have the same hashes")
+		}
+
+		if dck.Index != ck.Index || dck.PoW != ck.PoW || dck.Timestamp != ck.Timestamp || dck.Data != ck.Data || !bytes.Equal(dck.ExtraData, ck.ExtraData) {
+			t.Errorf("expected decoded chunk to match but got %+v", dck)
+		}
+	}
+
+	if dck, _ := DecodeHeader(getChunk(blk).EncodeHeader()); dck.Parent != nil {
+		t.Errorf("expected genesis chunk to have no parent")
+	}
+
+	h := getChunk(blk).EncodeHeader()
+	long := getChunk(blk)
+	long.ExtraData = make([]byte, MaxExtraData+1)
+	for _, b := range [][]byte{h[:HeaderSize-1], append(h, 'x'), long.EncodeHeader()} {
+		if _, err := DecodeHeader(b); err == nil {
+			t.Errorf("expected malformed header to return error")
+		}
+	}
+}
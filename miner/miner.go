@@ -3,27 +3,40 @@ package miner
 import (
 	"bytes"
 	"fmt"
-	"strconv"
 	"time"
 
 	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chainerr"
 )
 
 type (
 	// Miner implementation which much be adheard to for Block struct.
 	Miner interface {
-		Mine() (pow int)
+		Mine() (pow uint64, ok bool)
+		MineWithLimit(max uint64) (pow uint64, err error)
 		IsMined() bool
 		MarshalJSON() ([]byte, error)
 		Encode() (j []byte)
-		ValidatePoW(pow int) bool
+		ValidatePoW(pow uint64) bool
 		IsValidPoW() bool
 		GenerateHash(save bool) (sum []byte)
+		GetHash() []byte
+		GetParentHash() []byte
+		GetIndex() int
+		GetDifficulty() int
+		GetChainID() int
+		GetTimestamp() time.Time
+		ValidateHeader() error
+		ValidateBody() error
 		IsValid() bool
 	}
 
+	// Creates a new miner chained to the parent miner.
+	// Parent will be nil for a genesis block.
+	Factory func(parent Miner, dif int, data string) (Miner, error)
+
 	// Reprecents a block in the chain which contains the miner.
 	// The miner will contain a struct like "chunk" which implements the miner interface.
 	Block struct {
@@ -32,61 +45,141 @@ type (
 
 	// Reprecents a chunk and it's data used for mining.
 	Chunk struct {
-		Parent     *Chunk    `json:"-"`
-		Hash       []byte    `json:"hash"`
-		Index      int       `json:"index"`
-		PoW        int       `json:"pow"`
-		Difficulty int       `json:"difficulty"`
-		Data       string    `json:"data"`
-		Timestamp  time.Time `json:"timestamp"`
+		Parent     *Chunk   `json:"-"`
+		Hash       []byte   `json:"hash"`
+		Index      int      `json:"index"`
+		PoW        uint64   `json:"pow"`
+		ExtraNonce uint64   `json:"extra_nonce,omitempty"` // Bumped each time the PoW values wrap around.
+		Difficulty int      `json:"difficulty"`
+		Data       string   `json:"data"`
+		ExtraData  []byte   `json:"extra_data,omitempty"` // Set by the miner, such as a pool tag, up to MaxExtraData bytes.
+		Timestamp  int64    `json:"timestamp"`            // Unix milliseconds, UTC.
+		ChainID    int      `json:"chain_id,omitempty"`   // Network the chunk belongs to, inherited from the parent.
+		Options    *Options `json:"-"`                    // Mining options, inherited from the parent.
+	}
+
+	// Reprecents the result of a header rule checked against a chunk.
+	HeaderCheck struct {
+		Rule string
+		Err  error // Reason the rule failed, nil if it passed.
 	}
 )
 
 // Helper to create a new block based on a previous block.
+// This is a shortcut for NewWith using the chunk miner, it will panic if the
+// previous block does not hold a chunk. Use NewWith for custom miners.
 func New(blk *Block, dif int, data string) *Block {
+	nblk, err := NewWith(blk, NewChunk, dif, data)
+	if err != nil {
+		panic(err)
+	}
+
+	return nblk
+}
+
+// Creates a new block based on a previous block, using the factory to create the miner.
+// The previous block's miner is passed to the factory so it can chain to it.
+func NewWith(blk *Block, f Factory, dif int, data string) (*Block, error) {
+	var pm Miner // Previous miner (will be nil for genesis block)
+	if blk != nil {
+		pm = blk.Miner
+	}
+
+	m, err := f(pm, dif, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Block{Miner: m}, nil
+}
+
+// Factory for the built-in chunk miner.
+// If a parent is supplied, it must be a chunk.
+func NewChunk(parent Miner, dif int, data string) (Miner, error) {
 	var pck *Chunk // Previous chunk (will be nil for genesis block)
 	var ni int     // Next index to assign.
+	var cid int    // Chain ID to inherit.
+	var o *Options // Mining options to inherit.
 
 	// Determine if a normal block or genesis block.
-	if blk != nil {
+	if parent != nil {
 		// Previous block is present, we have a normal block.
-		pck = blk.Miner.(*Chunk)
+		ck, ok := parent.(*Chunk)
+		if !ok {
+			return nil, fmt.Errorf("can not create chunk, parent %w", chainerr.ErrInvalidMiner)
+		}
+
+		pck = ck
 		ni = pck.Index + 1
+		cid = pck.ChainID
+		o = pck.Options
 	}
 
-	return &Block{
-		Miner: &Chunk{
-			Parent:     pck,
-			Index:      ni,
-			Timestamp:  time.Now(),
-			Difficulty: dif,
-			Data:       data,
-		},
+	ck := &Chunk{
+		Parent:     pck,
+		Index:      ni,
+		Difficulty: dif,
+		Data:       data,
+		ChainID:    cid,
+		Options:    o,
 	}
+	ck.Timestamp = ck.GetOptions().now().UnixMilli()
+
+	return ck, nil
 }
 
 // Mines a chunk.
 // Will keep running until the PoW is valid and solved for the difficulty.
-func (ck *Chunk) Mine() (pow int) {
-	for {
-		if ck.ValidatePoW(pow) {
-			// Solved
-			break
-		}
+// If the max attempts of the chunk's options are reached first, false is returned and no PoW is saved.
+func (ck *Chunk) Mine() (pow uint64, ok bool) {
+	extra, pow, ok := ck.GetOptions().search(ck.Difficulty, ck.ExtraNonce, ck.newValidator)
+	if !ok {
+		return 0, false
+	}
 
-		// Not solved, increase.
-		pow++
+	// Save the PoW to the block.
+	ck.ExtraNonce, ck.PoW = extra, pow
+
+	return
+}
+
+// Mines a chunk, giving up after the max attempts.
+// If no PoW is found, chainerr.ErrPoWNotFound is returned and no PoW is saved, so the
+// caller can change the chunk and try again instead of mining a stale chunk forever.
+// A max of 0 tries nothing, so it returns chainerr.ErrPoWNotFound straight away.
+func (ck *Chunk) MineWithLimit(max uint64) (pow uint64, err error) {
+	if max == 0 {
+		return 0, chainerr.ErrPoWNotFound
+	}
+
+	o := *ck.GetOptions()
+	o.MaxAttempts = max
+
+	extra, pow, ok := o.search(ck.Difficulty, ck.ExtraNonce, ck.newValidator)
+	if !ok {
+		return 0, chainerr.ErrPoWNotFound
 	}
 
 	// Save the PoW to the block.
-	ck.PoW = pow
+	ck.ExtraNonce, ck.PoW = extra, pow
 
 	return
 }
 
-// Check if the chunk is mined. Simply checks it has a PoW value.
+// Gets the mining options of the chunk, or the defaults if it has none.
+func (ck Chunk) GetOptions() *Options {
+	if ck.Options == nil {
+		return defaultOptions
+	}
+
+	return ck.Options
+}
+
+// Check if the chunk is mined, by checking its PoW solves the difficulty.
+// A PoW of 0 is a valid solution like any other, so the PoW value alone can not mark a chunk as mined.
+// Chunks with no difficulty have nothing to solve, so are always mined.
 func (ck Chunk) IsMined() bool {
-	return ck.PoW > 0
+	return ck.IsValidPoW()
 }
 
 // Marshal for JSON encode.
@@ -114,22 +207,43 @@ func (ck Chunk) Encode() (j []byte) {
 	return
 }
 
-// Validates the PoW by combining parent chunk's PoW with input pow.
-// Adding both together and hashing, should equal the padding of the difficulty.
-func (ck Chunk) ValidatePoW(pow int) bool {
-	// Convert the PoW to strings and combine.
-	c := strconv.Itoa(ck.GetParent().PoW) + strconv.Itoa(pow)
+// Validates the PoW by combining parent chunk's PoW with input pow, using the chunk's extra nonce.
+// Hashing both together, should equal the padding of the difficulty.
+func (ck Chunk) ValidatePoW(pow uint64) bool {
+	return ck.validatePoW(ck.ExtraNonce, pow)
+}
+
+// Validates the PoW with the extra nonce.
+func (ck Chunk) validatePoW(extra, pow uint64) bool {
+	// No difficulty, any PoW will do.
+	if ck.Difficulty <= 0 {
+		return true
+	}
 
-	// Hash the combined PoW, convert the hash to string.
-	h := sha256.New()
-	h.Write([]byte(c))
-	sum := hex.EncodeToString(h.Sum(nil))
+	o := ck.GetOptions()
+	s := o.getScratch()
+	defer o.putScratch(s)
+
+	// Hash the combined PoW, testing for a "0" by the difficulty level.
+	s.setParent(ck.GetParent().PoW)
+
+	return s.solves(ck.Difficulty, extra, pow)
+}
 
-	// Pad a "0" string by the difficulty level.
+// Creates a PoW validator with its own scratch space, for a single mining worker.
+// The parent's PoW is written to the preimage once, so each attempt only writes the extra nonce and pow.
+func (ck Chunk) newValidator() func(extra, pow uint64) bool {
 	dif := ck.Difficulty
-	pad := fmt.Sprintf("%0*d", dif, 0)
+	if dif <= 0 {
+		return func(extra, pow uint64) bool { return true }
+	}
+
+	s := ck.GetOptions().newScratch()
+	s.setParent(ck.GetParent().PoW)
 
-	return sum[:dif] == pad
+	return func(extra, pow uint64) bool {
+		return s.solves(dif, extra, pow)
+	}
 }
 
 // Checks if this chunk's PoW is valid.
@@ -137,57 +251,148 @@ func (ck Chunk) IsValidPoW() bool {
 	return ck.ValidatePoW(ck.PoW)
 }
 
-// Generate a hash for the chunk based on the chunk's struct data in JSON format.
+// Generate a hash for the chunk based on the chunk's header.
 // Option to save or simply generate.
 func (ck *Chunk) GenerateHash(save bool) (sum []byte) {
-	// We can't generate a hash of a chunk with a hash.
-	// So this hash must be temp removed so we can recaulate.
-	osum := ck.Hash
-	ck.Hash = nil
-
-	// Encode the JSON of the struct to a hash.
-	h := sha256.New()
-	h.Write(ck.Encode())
-	sum = h.Sum(nil)
+	// Hash the fixed width header, the saved hash is not part of it.
+	b := headerPool.Get().(*[]byte)
+	*b = ck.appendHeader((*b)[:0])
+	h := sha256.Sum256(*b)
+	headerPool.Put(b)
+	sum = h[:]
 
 	if save {
 		// Save the new hash to the block.
 		ck.Hash = sum
-	} else {
-		// Put back the old hash to the block.
-		ck.Hash = osum
 	}
 
 	return
 }
 
-// Confirms the block validity.
-func (ck Chunk) IsValid() bool {
-	pok, bok := true, true
+// Gets the saved hash of the chunk.
+func (ck Chunk) GetHash() []byte {
+	return ck.Hash
+}
 
-	// Determine if hashes are reproduceable.
-	re := func(c Chunk) bool {
-		return bytes.Equal(c.GenerateHash(false), c.Hash)
+// Gets the saved hash of the parent chunk.
+// Will be nil for a genesis chunk.
+func (ck Chunk) GetParentHash() []byte {
+	return ck.GetParent().Hash
+}
+
+// Gets the index of the chunk, its height in the chain.
+func (ck Chunk) GetIndex() int {
+	return ck.Index
+}
+
+// Gets the difficulty of the chunk.
+func (ck Chunk) GetDifficulty() int {
+	return ck.Difficulty
+}
+
+// Gets the chain ID of the chunk.
+func (ck Chunk) GetChainID() int {
+	return ck.ChainID
+}
+
+// Gets the timestamp of the chunk, with millisecond precision.
+func (ck Chunk) GetTimestamp() time.Time {
+	return time.UnixMilli(ck.Timestamp)
+}
+
+// Validates the cheap header checks of the chunk: linkage, PoW, and timestamps.
+// These should be ran before the body checks so bad chunks are rejected early.
+// Returns the error of the first rule which fails.
+func (ck Chunk) ValidateHeader() error {
+	for _, hc := range ck.CheckHeader() {
+		if hc.Err != nil {
+			return hc.Err
+		}
+	}
+
+	return nil
+}
+
+// Checks every header rule of the chunk, without stopping at the first failure.
+// Genesis chunks have no parent, so the parent rules are not checked.
+func (ck Chunk) CheckHeader() (hcs []HeaderCheck) {
+	add := func(rule string, err error) {
+		hcs = append(hcs, HeaderCheck{Rule: rule, Err: err})
 	}
 
 	// Check if we have a parent chunk to check.
 	if !ck.IsGenesis() {
+		pck := ck.GetParent()
+
 		// Test parent chunk's index plus one, will equal this chunk's index.
+		var err error
+		if pck.Index+1 != ck.Index {
+			err = fmt.Errorf("%w, chunk index does not follow parent index", chainerr.ErrOrphanBlock)
+		}
+		add("index", err)
+
 		// Test the parent chunk's PoW is valid.
-		// Test the hash of parent chunk's hash is what is set for this chunk's parent hash.
-		pck := ck.GetParent()
-		if (pck.Index+1 == ck.Index && pck.IsValidPoW() && re(*pck)) == false {
-			pok = false
+		err = nil
+		if !pck.IsValidPoW() {
+			err = fmt.Errorf("parent chunk %w", chainerr.ErrInvalidPoW)
+		}
+		add("parent_pow", err)
+
+		// Test this chunk belongs to the same chain as its parent.
+		err = nil
+		if pck.ChainID != ck.ChainID {
+			err = fmt.Errorf("chunk %w", chainerr.ErrWrongChain)
 		}
+		add("parent_chain_id", err)
+
+		// Test this chunk was not created before its parent.
+		err = nil
+		if ck.Timestamp < pck.Timestamp {
+			err = fmt.Errorf("%w, chunk timestamp is before parent timestamp", chainerr.ErrBadTimestamp)
+		}
+		add("timestamp", err)
+	}
+
+	// Test the extra data is within its limit.
+	var err error
+	if len(ck.ExtraData) > MaxExtraData {
+		err = fmt.Errorf("chunk %w", chainerr.ErrExtraData)
+	}
+	add("extra_data", err)
+
+	// Test this chunk is mined with a valid PoW.
+	err = nil
+	if !ck.IsMined() {
+		err = fmt.Errorf("chunk %w", chainerr.ErrInvalidPoW)
+	}
+	add("pow", err)
+
+	return
+}
+
+// Validates the expensive body checks of the chunk: hash reproduction.
+func (ck Chunk) ValidateBody() error {
+	// Determine if hashes are reproduceable.
+	re := func(c Chunk) bool {
+		return bytes.Equal(c.GenerateHash(false), c.Hash)
+	}
+
+	// Test the hash of parent chunk's hash is what is set for this chunk's parent hash.
+	if !ck.IsGenesis() && !re(*ck.GetParent()) {
+		return fmt.Errorf("parent chunk %w", chainerr.ErrInvalidHash)
 	}
 
-	// Test this block is mined.
 	// Test this blocks hash is equal to a regeneration of the hash.
-	if (ck.IsMined() && re(ck)) == false {
-		bok = false
+	if !re(ck) {
+		return fmt.Errorf("chunk %w", chainerr.ErrInvalidHash)
 	}
 
-	return pok && bok
+	return nil
+}
+
+// Confirms the block validity, header checks are ran before body checks.
+func (ck Chunk) IsValid() bool {
+	return ck.ValidateHeader() == nil && ck.ValidateBody() == nil
 }
 
 // Determines if the current chunk is a genesis chunk.
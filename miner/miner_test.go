@@ -2,6 +2,7 @@ package miner
 
 import (
 	"encoding/hex"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -36,6 +37,49 @@ func TestNewMiner(t *testing.T) {
 	}
 }
 
+// Custom miner used to test miner factories.
+type customMiner struct {
+	*Chunk
+}
+
+// Factory for the custom miner.
+func newCustomMiner(parent Miner, dif int, data string) (Miner, error) {
+	ck := &Chunk{Difficulty: dif, Data: data, Timestamp: time.Now().UnixMilli()}
+	if parent != nil {
+		ck.Parent = parent.(*customMiner).Chunk
+		ck.Index = ck.Parent.Index + 1
+	}
+
+	return &customMiner{Chunk: ck}, nil
+}
+
+// Test a new block can be created with a custom miner factory.
+func TestNewWithFactory(t *testing.T) {
+	blk, err := NewWith(nil, newCustomMiner, 1, "One")
+	if err != nil {
+		t.Fatalf("expected block to be created but got %s", err)
+	}
+
+	blk2, err := NewWith(blk, newCustomMiner, 1, "Two")
+	if err != nil {
+		t.Fatalf("expected block to be created but got %s", err)
+	}
+
+	cm := (blk2.Miner).(*customMiner)
+	if cm.Index != 1 || cm.Parent != (blk.Miner).(*customMiner).Chunk {
+		t.Errorf("expected custom miner to be chained to its parent")
+	}
+}
+
+// Test the chunk factory will not chain to a foreign miner.
+func TestNewChunkWithForeignParent(t *testing.T) {
+	blk, _ := NewWith(nil, newCustomMiner, 1, "One")
+
+	if _, err := NewWith(blk, NewChunk, 1, "Two"); err == nil {
+		t.Errorf("expected chunk with a foreign parent to fail but resulted in success")
+	}
+}
+
 // Test miner ability to encode its struct to JSON data.
 func TestMinerEncode(t *testing.T) {
 	blk := createBlock()
@@ -43,21 +87,22 @@ func TestMinerEncode(t *testing.T) {
 
 	// Actual and expected.
 	a := string(ck.Encode())
-	e := "{\"parent_hash\":null,\"hash\":null,\"index\":0,\"pow\":0,\"difficulty\":1,\"data\":\"Hello World!\",\"timestamp\":\"" + ck.Timestamp.Format(time.RFC3339Nano) + "\"}"
+	e := "{\"parent_hash\":null,\"hash\":null,\"index\":0,\"pow\":0,\"difficulty\":1,\"data\":\"Hello World!\",\"timestamp\":" + strconv.FormatInt(ck.Timestamp, 10) + "}"
 
 	if a != e {
 		t.Errorf("expected encode of %s but got %s", a, e)
 	}
 }
 
-// Test miner ability to encode its stuct to JSON data and create hash of it.
+// Test miner ability to encode its header and create hash of it.
 func TestMinerGenerateHash(t *testing.T) {
 	blk := createBlock()
 	ck := getChunk(blk)
 
 	// Actual and expected.
+	// Expected is the SHA256 of the header preimage.
 	a := hex.EncodeToString(ck.GenerateHash(false))
-	e := "866da7defdc2df09616dea46e80ffa8c0ea66517a6bd1f70146c86b1b6a54efd"
+	e := "6183fdbf94640d6568a86b8c8d4c7ad83050622b056df1e04dea00d3d37f0c62"
 
 	if a != e {
 		t.Errorf("expected hash of %s but got %s", a, e)
@@ -73,25 +118,59 @@ func TestMinerMined(t *testing.T) {
 		t.Errorf("expected miner to have mined")
 	}
 
-	ck.PoW = 1
+	ck.PoW = 5
 	if !ck.IsMined() {
 		t.Errorf("expected miner to not have mined")
 	}
 }
 
+// Test chunks solved by a PoW of 0, with or without an extra nonce, are mined.
+func TestMinerMinedZeroPoW(t *testing.T) {
+	var prev *Block
+	for i := 0; ; i++ {
+		if i == 1000 {
+			t.Fatalf("expected a chunk to be solved by a PoW of 0")
+		}
+
+		blk := New(prev, 1, "")
+		blk.Mine()
+		blk.GenerateHash(true)
+		prev = blk
+
+		if ck := getChunk(blk); ck.PoW == 0 {
+			if !ck.IsMined() || ck.ValidateHeader() != nil {
+				t.Errorf("expected chunk with a PoW of 0 to be mined")
+			}
+
+			// An extra nonce alone does not make a chunk mined.
+			ck.ExtraNonce = 1
+			for ck.IsValidPoW() {
+				ck.PoW++
+			}
+
+			if ck.IsMined() {
+				t.Errorf("expected chunk with an unsolved PoW and an extra nonce to not be mined")
+			}
+
+			break
+		}
+	}
+}
+
 // Check miner PoW can validate.
 func TestMineValidateNonce(t *testing.T) {
 	// With a difficulty of "1".
 	// And a parent chunk PoW of "0".
-	// It should take "3" tries to solve the problem.
-	// Because a SHA256 hash of "0" + "3" a a string,
-	// Will equal a hash of "0b8efa5a3bf104413a725c6ff0459a6be12b1fd33314cbb138745baf39504ae5",
+	// And an extra nonce of "0".
+	// It should take "6" tries to solve the problem.
+	// Because a SHA256 hash of 0, 0, and 5 as big-endian uint64s,
+	// Will equal a hash of "0b3be4b3fbc2555aa30cddb240f351a14ecb21c883ff23854b762c7cdfa85c05",
 	// Which then "0"[:difficulty] == "0".
 
 	blk := createBlock()
 	ck := getChunk(blk)
 
-	n := 3                   // PoW of 3
+	n := uint64(5)           // PoW of 5
 	res := ck.ValidatePoW(n) // result
 
 	if !res {
@@ -108,13 +187,13 @@ func TestMineValidateNonce(t *testing.T) {
 // Test the miner runs the solution to produce a valid PoW and become "mined".
 func TestMinerMines(t *testing.T) {
 	// Given our solution for validate PoW,
-	// A difficulty of "1", should produce an PoW of "3".
+	// A difficulty of "1", should produce an PoW of "5".
 	blk := createBlock()
 	ck := getChunk(blk)
 	ck.Mine()
 
-	if ck.PoW != 3 {
-		t.Errorf("expected miner to have mined with a PoW result of 3 but failed")
+	if ck.PoW != 5 {
+		t.Errorf("expected miner to have mined with a PoW result of 5 but failed")
 	}
 
 	if !ck.IsMined() {
@@ -142,7 +221,7 @@ func TestMinerIsNotValid(t *testing.T) {
 	ck.GenerateHash(true)
 
 	// Fudge the data.
-	ck.Timestamp = time.Now()
+	ck.Timestamp = time.Now().UnixMilli()
 
 	if ck.IsValid() {
 		t.Errorf("expected miner to be invalid but it returned as valid")
@@ -157,7 +236,7 @@ func TestMinerValidatesWithParentChunk(t *testing.T) {
 		Index:      0,
 		Difficulty: 1,
 		Data:       "Hello World",
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UnixMilli(),
 	}
 
 	ck.Mine()
@@ -169,7 +248,7 @@ func TestMinerValidatesWithParentChunk(t *testing.T) {
 		Index:      1,
 		Difficulty: 1,
 		Data:       "Hello World, Again",
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UnixMilli(),
 	}
 
 	ck2.Mine()
@@ -189,7 +268,7 @@ func TestMinerIsNotValidWithParentMiner(t *testing.T) {
 		Index:      1,
 		Difficulty: 1,
 		Data:       "Hello World",
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UnixMilli(),
 	}
 
 	ck.Mine()
@@ -201,7 +280,7 @@ func TestMinerIsNotValidWithParentMiner(t *testing.T) {
 		Index:      1, // Change the index
 		Difficulty: 1,
 		Data:       "Hellow World, Again",
-		Timestamp:  time.Now(),
+		Timestamp:  time.Now().UnixMilli(),
 	}
 
 	ck2.Mine()
@@ -212,6 +291,27 @@ func TestMinerIsNotValidWithParentMiner(t *testing.T) {
 	}
 }
 
+// Test a chunk with a different chain ID to its parent fails to validate.
+func TestMinerIsNotValidWithOtherChainID(t *testing.T) {
+	ck := &Chunk{Difficulty: 1, Timestamp: time.Now().UnixMilli(), ChainID: 1}
+	ck.Mine()
+	ck.GenerateHash(true)
+
+	ck2 := &Chunk{Parent: ck, Index: 1, Difficulty: 1, Timestamp: time.Now().UnixMilli(), ChainID: 2}
+	ck2.Mine()
+	ck2.GenerateHash(true)
+
+	if ck2.IsValid() {
+		t.Errorf("expected miner to be invalid but result was valid")
+	}
+
+	ck2.ChainID = 1
+	ck2.GenerateHash(true)
+	if !ck2.IsValid() {
+		t.Errorf("expected miner to validate but failed")
+	}
+}
+
 // Create a plain miner implementation for the test to use.
 func createBlock() (blk *Block) {
 	// Create the block with an empty previous.
@@ -219,7 +319,7 @@ func createBlock() (blk *Block) {
 
 	// Get the chunk created
 	ck := getChunk(blk)
-	ck.Timestamp = time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC) // Change the timestamp to something testable.
+	ck.Timestamp = time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC).UnixMilli() // Change the timestamp to something testable.
 
 	return
 }
@@ -0,0 +1,283 @@
+package miner
+
+import (
+	"hash"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ohmybrew/gochain/clock"
+)
+
+type (
+	// Reprecents the progress of mining.
+	Progress struct {
+		Attempts uint64        `json:"attempts"` // PoW values tried so far.
+		Elapsed  time.Duration `json:"elapsed"`  // Time spent mining so far.
+		Rate     float64       `json:"rate"`     // Attempts per second.
+		Expected float64       `json:"expected"` // Attempts expected to find the PoW for the difficulty.
+		ETA      time.Duration `json:"eta"`      // Estimated time left to find the PoW at the current rate.
+	}
+
+	// Configuration for mining a chunk.
+	// Options are configured once and reused by every chunk created with their factory.
+	Options struct {
+		Workers       int              // Goroutines searching for the PoW, 1 if not set.
+		StartPoW      uint64           // PoW to start searching from.
+		MaxAttempts   uint64           // PoW values to try before giving up, unlimited if zero.
+		ProgressEvery uint64           // Attempts between calls to the progress callback.
+		OnProgress    func(p Progress) // Progress callback, may be called from multiple workers.
+		Hash          func() hash.Hash // Hashing algorithm for the PoW, SHA256 if not set.
+		Nonces        NonceSource      // PoW values to try instead of searching, workers and start PoW are ignored.
+		Clock         clock.Clock      // Time source for chunk timestamps and progress, the system clock if not set.
+	}
+
+	// Functional option to configure mining.
+	Option func(o *Options)
+
+	// Provides the PoW values to try, in order.
+	// Returns false once there are no more values.
+	NonceSource func() (pow uint64, ok bool)
+)
+
+// Options used by chunks without any.
+var defaultOptions = new(Options)
+
+// Creates new mining options.
+func NewOptions(opts ...Option) *Options {
+	o := new(Options)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Searches for the PoW with multiple workers.
+func WithWorkers(n int) Option {
+	return func(o *Options) {
+		o.Workers = n
+	}
+}
+
+// Starts searching for the PoW from the value.
+func WithStartPoW(pow uint64) Option {
+	return func(o *Options) {
+		o.StartPoW = pow
+	}
+}
+
+// Gives up mining after the amount of attempts.
+func WithMaxAttempts(n uint64) Option {
+	return func(o *Options) {
+		o.MaxAttempts = n
+	}
+}
+
+// Calls the callback every amount of attempts.
+func WithProgress(every uint64, fn func(p Progress)) Option {
+	return func(o *Options) {
+		o.ProgressEvery, o.OnProgress = every, fn
+	}
+}
+
+// Uses the hashing algorithm for the PoW.
+// Validators of the chunk must use the same algorithm.
+func WithHash(h func() hash.Hash) Option {
+	return func(o *Options) {
+		o.Hash = h
+	}
+}
+
+// Tries the PoW values from the source instead of searching for them.
+// Useful for tests, where a known solution can be supplied to mine high difficulties instantly.
+func WithNonceSource(src NonceSource) Option {
+	return func(o *Options) {
+		o.Nonces = src
+	}
+}
+
+// Reads the time from the clock instead of the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// Creates a nonce source which provides the PoW values in order.
+// The source is used up as it is tried, even across chunks.
+func Sequence(pows ...uint64) NonceSource {
+	var mu sync.Mutex
+
+	return func() (uint64, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(pows) == 0 {
+			return 0, false
+		}
+
+		pow := pows[0]
+		pows = pows[1:]
+
+		return pow, true
+	}
+}
+
+// Creates a factory for chunks which mine with these options.
+// Chunks created from a parent chunk inherit its options, so the factory is only needed for genesis.
+func (o *Options) Factory() Factory {
+	return func(parent Miner, dif int, data string) (Miner, error) {
+		m, err := NewChunk(parent, dif, data)
+		if err != nil {
+			return nil, err
+		}
+
+		ck := m.(*Chunk)
+		ck.Options = o
+		ck.Timestamp = o.now().UnixMilli()
+
+		return ck, nil
+	}
+}
+
+// Gets the current time from the clock.
+func (o *Options) now() time.Time {
+	if o.Clock == nil {
+		return clock.System.Now()
+	}
+
+	return o.Clock.Now()
+}
+
+// Creates the progress of mining for the difficulty.
+// Each attempt is independent, so the expected attempts left are the same no matter how many were made.
+// Each hex character of the hash has a 1 in 16 chance of being "0", so 16^difficulty attempts are expected.
+func newProgress(dif int, attempts uint64, elapsed time.Duration) (p Progress) {
+	p.Attempts, p.Elapsed = attempts, elapsed
+	p.Expected = math.Pow(16, float64(dif))
+
+	if s := elapsed.Seconds(); s > 0 {
+		p.Rate = float64(attempts) / s
+		p.ETA = time.Duration(p.Expected / p.Rate * float64(time.Second))
+	}
+
+	return
+}
+
+// Searches for a PoW which passes validation for the difficulty, starting with the extra nonce.
+// Once every PoW has been tried the search wraps, continuing from 0 with the next extra nonce.
+// Each worker creates its own validator, so validators need not be safe for concurrent use.
+// Returns false if the max attempts were reached, or the nonce source ran out, first.
+func (o *Options) search(dif int, extra uint64, newValidate func() func(extra, pow uint64) bool) (uint64, uint64, bool) {
+	var attempts uint64
+	start := o.now()
+
+	// Tries a PoW, returns false for more once the max attempts are reached.
+	try := func(validate func(extra, pow uint64) bool, extra, pow uint64) (solved, more bool) {
+		n := atomic.AddUint64(&attempts, 1)
+		if o.MaxAttempts > 0 && n > o.MaxAttempts {
+			// Gave up.
+			return false, false
+		}
+
+		if o.OnProgress != nil && o.ProgressEvery > 0 && n%o.ProgressEvery == 0 {
+			o.OnProgress(newProgress(dif, n, o.now().Sub(start)))
+		}
+
+		return validate(extra, pow), true
+	}
+
+	// Values were supplied, try them in order with the extra nonce as is.
+	if o.Nonces != nil {
+		validate := newValidate()
+		for {
+			pow, ok := o.Nonces()
+			if !ok {
+				return 0, 0, false
+			}
+
+			solved, more := try(validate, extra, pow)
+			if solved {
+				return extra, pow, true
+			}
+
+			if !more {
+				return 0, 0, false
+			}
+		}
+	}
+
+	w := uint64(o.Workers)
+	if w < 1 {
+		w = 1
+	}
+
+	for from := o.StartPoW; ; from = 0 {
+		newTry := func() func(pow uint64) (bool, bool) {
+			validate := newValidate()
+
+			return func(pow uint64) (bool, bool) { return try(validate, extra, pow) }
+		}
+
+		if pow, ok := o.searchRange(w, from, newTry); ok {
+			return extra, pow, true
+		}
+
+		// Gave up, or every extra nonce was tried as well.
+		if (o.MaxAttempts > 0 && atomic.LoadUint64(&attempts) >= o.MaxAttempts) || extra == math.MaxUint64 {
+			return 0, 0, false
+		}
+
+		extra++
+	}
+}
+
+// Searches the PoW values from the value up to the max with the workers, each with its own try.
+// Returns false if the range was used up, or the max attempts were reached, first.
+func (o *Options) searchRange(w, from uint64, newTry func() func(pow uint64) (solved, more bool)) (uint64, bool) {
+	var wg sync.WaitGroup
+	found := make(chan uint64, w)
+	stop := make(chan struct{})
+
+	// Each worker tries every w'th PoW.
+	for i := uint64(0); i < w && from+i >= from; i++ {
+		wg.Add(1)
+		go func(pow uint64) {
+			defer wg.Done()
+			try := newTry()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				solved, more := try(pow)
+				if solved {
+					found <- pow
+					return
+				}
+
+				// Stop before the PoW wraps around.
+				if !more || pow > math.MaxUint64-w {
+					return
+				}
+
+				pow += w
+			}
+		}(from + i)
+	}
+
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	pow, ok := <-found
+	close(stop)
+
+	return pow, ok
+}
@@ -0,0 +1,199 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"crypto/sha512"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/clock"
+)
+
+// Test mining with multiple workers finds a valid PoW.
+func TestMineWithWorkers(t *testing.T) {
+	o := NewOptions(WithWorkers(4))
+	blk, _ := NewWith(nil, o.Factory(), 2, "One")
+	blk2 := New(blk, 2, "Two")
+
+	if getChunk(blk2).Options != o {
+		t.Errorf("expected options to be inherited from the parent")
+	}
+
+	for _, b := range []*Block{blk, blk2} {
+		if pow, ok := b.Mine(); !ok || !b.IsValidPoW() {
+			t.Errorf("expected a valid PoW but got %d", pow)
+		}
+	}
+}
+
+// Test mining starts from the start PoW.
+func TestMineWithStartPoW(t *testing.T) {
+	// A PoW of 5 solves a difficulty of 1, starting past it finds the next.
+	blk, _ := NewWith(nil, NewOptions(WithStartPoW(6)).Factory(), 1, "One")
+	if pow, _ := blk.Mine(); pow <= 5 || !blk.IsValidPoW() {
+		t.Errorf("expected a valid PoW past 5 but got %d", pow)
+	}
+}
+
+// Test mining gives up after the max attempts.
+func TestMineWithMaxAttempts(t *testing.T) {
+	// A PoW of 5 solves a difficulty of 1, which takes 6 attempts.
+	blk, _ := NewWith(nil, NewOptions(WithMaxAttempts(5)).Factory(), 1, "One")
+	if pow, ok := blk.Mine(); ok || blk.IsMined() {
+		t.Errorf("expected mining to give up but got %d", pow)
+	}
+
+	getChunk(blk).Options = NewOptions(WithMaxAttempts(6))
+	if pow, ok := blk.Mine(); !ok || pow != 5 {
+		t.Errorf("expected a PoW of 5 but got %d", pow)
+	}
+}
+
+// Test mining with a limit returns an explicit not found result.
+func TestMineWithLimit(t *testing.T) {
+	blk := createBlock()
+
+	// No attempts gives up straight away, instead of meaning unlimited.
+	if _, err := blk.MineWithLimit(0); !errors.Is(err, chainerr.ErrPoWNotFound) {
+		t.Errorf("expected PoW not found error but got %v", err)
+	}
+
+	// A PoW of 5 solves a difficulty of 1, which takes 6 attempts.
+	if _, err := blk.MineWithLimit(5); !errors.Is(err, chainerr.ErrPoWNotFound) {
+		t.Errorf("expected PoW not found error but got %v", err)
+	}
+
+	if blk.IsMined() {
+		t.Errorf("expected no PoW to be saved")
+	}
+
+	if pow, err := blk.MineWithLimit(6); err != nil || pow != 5 || !blk.IsMined() {
+		t.Errorf("expected a PoW of 5 but got %d", pow)
+	}
+}
+
+// Test the progress callback is called.
+func TestMineWithProgress(t *testing.T) {
+	var calls uint64
+	o := NewOptions(WithProgress(1, func(p Progress) {
+		atomic.AddUint64(&calls, 1)
+	}))
+
+	blk, _ := NewWith(nil, o.Factory(), 1, "One")
+	blk.Mine()
+
+	// A PoW of 5 solves a difficulty of 1, which takes 6 attempts.
+	if calls != 6 {
+		t.Errorf("expected 6 progress calls but got %d", calls)
+	}
+}
+
+// Test the progress reports the rate and estimated time.
+func TestNewProgress(t *testing.T) {
+	p := newProgress(2, 128, 2*time.Second)
+
+	if p.Rate != 64 || p.Expected != 256 || p.ETA != 4*time.Second {
+		t.Errorf("expected a rate of 64, 256 expected attempts, and an ETA of 4s but got %+v", p)
+	}
+
+	if p := newProgress(1, 1, 0); p.Rate != 0 || p.ETA != 0 {
+		t.Errorf("expected no rate or ETA without elapsed time but got %+v", p)
+	}
+}
+
+// Test mining with a known solution is instant for high difficulties.
+func TestMineWithNonceSource(t *testing.T) {
+	// A PoW of 401400 solves a difficulty of 5 for a genesis chunk.
+	o := NewOptions(WithNonceSource(Sequence(1, 2, 401400)))
+	blk, _ := NewWith(nil, o.Factory(), 5, "One")
+
+	if pow, _ := blk.Mine(); pow != 401400 || !blk.IsValidPoW() {
+		t.Errorf("expected a PoW of 401400 but got %d", pow)
+	}
+
+	// Source is used up.
+	if _, err := blk.MineWithLimit(0); !errors.Is(err, chainerr.ErrPoWNotFound) {
+		t.Errorf("expected PoW not found error but got %v", err)
+	}
+}
+
+// Test mining wraps the PoW around and bumps the extra nonce once every value is tried.
+func TestMineWrapsExtraNonce(t *testing.T) {
+	// No PoW from the last 3 solves a difficulty of 2 for a genesis chunk,
+	// with an extra nonce of 1 a PoW of 49 is the first which does.
+	for _, w := range []int{1, 2, 4} {
+		o := NewOptions(WithStartPoW(math.MaxUint64-2), WithWorkers(w))
+		blk, _ := NewWith(nil, o.Factory(), 2, "One")
+		ck := getChunk(blk)
+
+		// Any worker may find a solution first.
+		if pow, ok := blk.Mine(); !ok || (w == 1 && pow != 49) || ck.ExtraNonce != 1 || !blk.IsValidPoW() {
+			t.Errorf("expected a PoW with an extra nonce of 1 but got %d and %d", pow, ck.ExtraNonce)
+		}
+
+		ck.GenerateHash(true)
+		if !blk.IsValid() {
+			t.Errorf("expected chunk to validate but failed")
+		}
+	}
+
+	// Max attempts are counted across extra nonces.
+	blk, _ := NewWith(nil, NewOptions(WithStartPoW(math.MaxUint64-2), WithMaxAttempts(10)).Factory(), 2, "One")
+	if _, ok := blk.Mine(); ok || blk.IsMined() {
+		t.Errorf("expected mining to give up")
+	}
+}
+
+// Test the full range of PoW values can be used.
+func TestMineMaxPoW(t *testing.T) {
+	// The max PoW solves a difficulty of 1 for a genesis chunk.
+	blk, _ := NewWith(nil, NewOptions(WithStartPoW(math.MaxUint64)).Factory(), 1, "One")
+	if pow, ok := blk.Mine(); !ok || pow != math.MaxUint64 || getChunk(blk).ExtraNonce != 0 {
+		t.Errorf("expected the max PoW but got %d", pow)
+	}
+
+	if e := fmt.Sprintf("\"pow\":%d", uint64(math.MaxUint64)); !strings.Contains(string(blk.Encode()), e) {
+		t.Errorf("expected %s in the encoded chunk", e)
+	}
+}
+
+// Test mining with another hashing algorithm.
+func TestMineWithHash(t *testing.T) {
+	blk, _ := NewWith(nil, NewOptions(WithHash(sha512.New)).Factory(), 2, "One")
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	if !blk.IsValid() {
+		t.Errorf("expected chunk to validate but failed")
+	}
+
+	// Validating with another algorithm should fail.
+	getChunk(blk).Options = nil
+	if blk.IsValidPoW() {
+		t.Errorf("expected PoW to be invalid with SHA256")
+	}
+}
+
+// Test chunks are timestamped with the clock.
+func TestMineWithClock(t *testing.T) {
+	ts := time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+	clk := clock.NewManual(ts)
+
+	blk, _ := NewWith(nil, NewOptions(WithClock(clk)).Factory(), 1, "One")
+	clk.Advance(time.Second)
+	blk2 := New(blk, 1, "Two")
+
+	if ck := getChunk(blk); !ck.GetTimestamp().Equal(ts) {
+		t.Errorf("expected timestamp of %s but got %s", ts, ck.GetTimestamp())
+	}
+
+	if e, ck := ts.Add(time.Second), getChunk(blk2); !ck.GetTimestamp().Equal(e) {
+		t.Errorf("expected timestamp of %s but got %s", e, ck.GetTimestamp())
+	}
+}
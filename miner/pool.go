@@ -0,0 +1,94 @@
+package miner
+
+import (
+	"hash"
+	"sync"
+
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Scratch space for PoW attempts, reused between attempts so the hot path does not allocate.
+// The preimage is the parent chunk's PoW followed by the extra nonce and the pow, all fixed width and big-endian.
+// Only the extra nonce and pow are written per attempt.
+type scratch struct {
+	h   hash.Hash
+	pre [24]byte // PoW preimage.
+	sum []byte   // Hash of the preimage.
+}
+
+var (
+	// Scratch space for SHA256, the default PoW hash.
+	scratchPool = sync.Pool{New: func() any { return newScratch(sha256.New()) }}
+
+	// Buffers for header preimages.
+	headerPool = sync.Pool{New: func() any {
+		b := make([]byte, 0, HeaderSize+MaxExtraData+256)
+
+		return &b
+	}}
+)
+
+// Creates scratch space for the hash.
+func newScratch(h hash.Hash) *scratch {
+	return &scratch{h: h, sum: make([]byte, 0, h.Size())}
+}
+
+// Sets the parent chunk's PoW of the preimage.
+func (s *scratch) setParent(pow uint64) {
+	binary.BigEndian.PutUint64(s.pre[0:], pow)
+}
+
+// Hashes the preimage with the extra nonce and pow, testing it solves the difficulty.
+func (s *scratch) solves(dif int, extra, pow uint64) bool {
+	binary.BigEndian.PutUint64(s.pre[8:], extra)
+	binary.BigEndian.PutUint64(s.pre[16:], pow)
+
+	s.h.Reset()
+	s.h.Write(s.pre[:])
+	s.sum = s.h.Sum(s.sum[:0])
+
+	return hasZeros(s.sum, dif)
+}
+
+// Tests the hash starts with a "0" hex character for each level of difficulty, without encoding it to hex.
+// Each byte holds two hex characters, high nibble first.
+func hasZeros(sum []byte, dif int) bool {
+	if dif > 2*len(sum) {
+		return false
+	}
+
+	for _, b := range sum[:dif/2] {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return dif%2 == 0 || sum[dif/2]>>4 == 0
+}
+
+// Creates scratch space for the PoW hash of the options, outside of the pool.
+func (o *Options) newScratch() *scratch {
+	if o.Hash != nil {
+		return newScratch(o.Hash())
+	}
+
+	return newScratch(sha256.New())
+}
+
+// Gets scratch space for the PoW hash of the options.
+// Custom hashes are not pooled, as they can differ between options.
+func (o *Options) getScratch() *scratch {
+	if o.Hash != nil {
+		return o.newScratch()
+	}
+
+	return scratchPool.Get().(*scratch)
+}
+
+// Returns the scratch space to the pool once the attempt is done.
+func (o *Options) putScratch(s *scratch) {
+	if o.Hash == nil {
+		scratchPool.Put(s)
+	}
+}
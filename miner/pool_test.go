@@ -0,0 +1,50 @@
+package miner
+
+import (
+	"strings"
+	"testing"
+
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Test leading zeros are counted per hex character, matching the hex encoding.
+func TestHasZeros(t *testing.T) {
+	for _, s := range []string{"", "0", "00", "000f", "0f", "f0", "00000a"} {
+		sum, _ := hex.DecodeString(strings.Repeat("0", len(s)%2) + s)
+		h := hex.EncodeToString(sum)
+
+		for dif := 0; dif <= len(h)+1; dif++ {
+			e := dif <= len(h) && strings.Repeat("0", dif) == h[:dif]
+			if a := hasZeros(sum, dif); a != e {
+				t.Errorf("expected %t for difficulty %d of %s but got %t", e, dif, h, a)
+			}
+		}
+	}
+}
+
+// Test PoW attempts do not allocate.
+// Only the validator's own scratch is checked, the pool used by ValidatePoW can be cleared at any GC.
+func TestValidatePoWAllocs(t *testing.T) {
+	ck := getChunk(createBlock())
+	ck.Difficulty = 4
+	v := ck.newValidator()
+
+	if n := testing.AllocsPerRun(100, func() { v(0, 1) }); n != 0 {
+		t.Errorf("expected no allocations per attempt but got %f", n)
+	}
+}
+
+// Test validators with custom hashes match validation.
+func TestNewValidatorCustomHash(t *testing.T) {
+	ck := getChunk(createBlock())
+	ck.Difficulty = 1
+	ck.Options = NewOptions(WithHash(sha256.New))
+
+	v := ck.newValidator()
+	for pow := uint64(0); pow < 64; pow++ {
+		if v(0, pow) != ck.ValidatePoW(pow) {
+			t.Errorf("expected validator to match validation for %d", pow)
+		}
+	}
+}
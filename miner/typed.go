@@ -0,0 +1,117 @@
+package miner
+
+import (
+	"fmt"
+
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+type (
+	// Codec converts a typed payload to and from the string data held by a chunk.
+	Codec[T any] interface {
+		Marshal(v T) (string, error)
+		Unmarshal(data string) (T, error)
+	}
+
+	// Codec which encodes payloads as JSON.
+	JSONCodec[T any] struct{}
+
+	// Reprecents a block whose chunk data holds a payload of type T.
+	// The payload is compile-time checked and encoded through the codec.
+	TypedBlock[T any] struct {
+		*Block
+		Codec Codec[T] // Codec for the payload, JSONCodec is used if nil.
+	}
+)
+
+// Marshals the payload to JSON.
+func (JSONCodec[T]) Marshal(v T) (string, error) {
+	j, err := json.Marshal(v)
+
+	return string(j), err
+}
+
+// Unmarshals the payload from JSON.
+func (JSONCodec[T]) Unmarshal(data string) (v T, err error) {
+	err = json.Unmarshal([]byte(data), &v)
+
+	return
+}
+
+// Helper to create a new typed block based on a previous block.
+// If codec is nil, the payload will be encoded as JSON.
+// If the previous block does not hold a chunk, error is returned.
+func NewTyped[T any](blk *Block, dif int, v T, c Codec[T]) (*TypedBlock[T], error) {
+	if c == nil {
+		c = JSONCodec[T]{}
+	}
+
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	nblk, err := NewWith(blk, NewChunk, dif, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedBlock[T]{
+		Block: nblk,
+		Codec: c,
+	}, nil
+}
+
+// Gets the chunk held by the block.
+// If the block does not hold a chunk, error is returned.
+func (tb TypedBlock[T]) Chunk() (*Chunk, error) {
+	if tb.Block == nil {
+		return nil, fmt.Errorf("can not get chunk, typed block %w", chainerr.ErrInvalidMiner)
+	}
+
+	ck, ok := tb.Miner.(*Chunk)
+	if !ok {
+		return nil, fmt.Errorf("can not get chunk, typed block %w", chainerr.ErrInvalidMiner)
+	}
+
+	return ck, nil
+}
+
+// Decodes the payload from the chunk's data.
+func (tb TypedBlock[T]) Payload() (v T, err error) {
+	ck, err := tb.Chunk()
+	if err != nil {
+		return
+	}
+
+	return tb.codec().Unmarshal(ck.Data)
+}
+
+// Encodes the payload into the chunk's data.
+// The chunk will need to be mined and hashed again afterwards.
+func (tb TypedBlock[T]) SetPayload(v T) error {
+	ck, err := tb.Chunk()
+	if err != nil {
+		return err
+	}
+
+	data, err := tb.codec().Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ck.Data = data
+
+	return nil
+}
+
+// Gets the block's codec, or the JSON codec if it has none.
+func (tb TypedBlock[T]) codec() Codec[T] {
+	if tb.Codec == nil {
+		return JSONCodec[T]{}
+	}
+
+	return tb.Codec
+}
@@ -0,0 +1,113 @@
+package miner
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chainerr"
+)
+
+// Payload used for typed block tests.
+type transfer struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int    `json:"amount"`
+}
+
+// Codec which stores an int as a plain string.
+type intCodec struct{}
+
+func (intCodec) Marshal(v int) (string, error) {
+	return strconv.Itoa(v), nil
+}
+
+func (intCodec) Unmarshal(data string) (int, error) {
+	return strconv.Atoi(data)
+}
+
+// Test a typed block encodes and decodes its payload as JSON by default.
+func TestNewTyped(t *testing.T) {
+	p := transfer{From: "alice", To: "bob", Amount: 5}
+	tb, err := NewTyped[transfer](nil, 1, p, nil)
+	if err != nil {
+		t.Fatalf("expected typed block to be created but got %s", err)
+	}
+
+	ck, err := tb.Chunk()
+	if err != nil {
+		t.Fatalf("expected chunk but got %s", err)
+	}
+
+	e := "{\"from\":\"alice\",\"to\":\"bob\",\"amount\":5}"
+	if ck.Data != e {
+		t.Errorf("expected data to be %s but got %s", e, ck.Data)
+	}
+
+	a, err := tb.Payload()
+	if err != nil || a != p {
+		t.Errorf("expected payload to be %v but got %v", p, a)
+	}
+}
+
+// Test a typed block can use a custom codec and be chained.
+func TestTypedWithCodec(t *testing.T) {
+	tb, _ := NewTyped[int](nil, 1, 1, intCodec{})
+	tb2, _ := NewTyped[int](tb.Block, 1, 2, intCodec{})
+
+	ck, _ := tb.Chunk()
+	ck2, _ := tb2.Chunk()
+	if ck2.Parent != ck {
+		t.Errorf("expected parent to match")
+	}
+
+	if err := tb2.SetPayload(42); err != nil {
+		t.Errorf("expected payload to be set but got %s", err)
+	}
+
+	if a, _ := tb2.Payload(); a != 42 {
+		t.Errorf("expected payload to be 42 but got %d", a)
+	}
+}
+
+// Test a typed block returns an error instead of panicking when its miner is not a chunk.
+func TestTypedNotChunk(t *testing.T) {
+	pm, _ := newCustomMiner(nil, 1, "")
+	if _, err := NewTyped[int](&Block{Miner: pm}, 1, 1, intCodec{}); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	tb := TypedBlock[int]{Block: &Block{Miner: pm}, Codec: intCodec{}}
+	if _, err := tb.Chunk(); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	if _, err := tb.Payload(); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	if err := tb.SetPayload(1); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	// Zero value has no block.
+	var zb TypedBlock[int]
+	if _, err := zb.Payload(); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	if err := zb.SetPayload(1); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+
+	// Without a codec, JSON is used.
+	nb := TypedBlock[transfer]{Block: New(nil, 1, "")}
+	p := transfer{From: "alice", To: "bob", Amount: 5}
+	if err := nb.SetPayload(p); err != nil {
+		t.Fatalf("expected payload to be set but got %s", err)
+	}
+
+	if a, err := nb.Payload(); err != nil || a != p {
+		t.Errorf("expected payload to be %v but got %v", p, a)
+	}
+}
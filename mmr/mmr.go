@@ -0,0 +1,171 @@
+package mmr
+
+import (
+	"bytes"
+	"errors"
+
+	"crypto/sha256"
+)
+
+type (
+	// Reprecents a Merkle Mountain Range, an append only set of perfect binary trees.
+	// The roots of the trees are the peaks, which are bagged together into a single root.
+	MMR struct {
+		levels [][][]byte // Nodes at each height, the leaves being height 0.
+	}
+
+	// Reprecents a proof that a leaf is in the range.
+	Proof struct {
+		Index    int      `json:"index"`    // Index of the leaf.
+		Siblings [][]byte `json:"siblings"` // Sibling hashes from the leaf up to its peak.
+		Peak     int      `json:"peak"`     // Index of the leaf's peak.
+		Peaks    [][]byte `json:"peaks"`    // All peaks, highest first.
+	}
+)
+
+// Creates a new range.
+func New() *MMR {
+	return new(MMR)
+}
+
+// Hashes a leaf, prefixed so a leaf can not be mistaken for a node.
+func hashLeaf(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0}, data...))
+
+	return sum[:]
+}
+
+// Hashes two nodes together.
+func hashNode(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{1})
+	h.Write(l)
+	h.Write(r)
+
+	return h.Sum(nil)
+}
+
+// Bags the peaks into a single root, folding from the lowest peak.
+func bag(peaks [][]byte) (root []byte) {
+	for i := len(peaks) - 1; i >= 0; i-- {
+		if root == nil {
+			root = peaks[i]
+		} else {
+			root = hashNode(peaks[i], root)
+		}
+	}
+
+	return
+}
+
+// Gets the amount of leaves.
+func (m MMR) Leaves() int {
+	if len(m.levels) == 0 {
+		return 0
+	}
+
+	return len(m.levels[0])
+}
+
+// Appends a leaf, merging trees of the same height.
+// Returns the index of the leaf.
+func (m *MMR) Append(data []byte) int {
+	n := hashLeaf(data)
+	for h := 0; ; h++ {
+		if h == len(m.levels) {
+			m.levels = append(m.levels, nil)
+		}
+
+		m.levels[h] = append(m.levels[h], n)
+
+		// Odd node count, nothing to merge with.
+		l := len(m.levels[h])
+		if l%2 == 1 {
+			break
+		}
+
+		n = hashNode(m.levels[h][l-2], m.levels[h][l-1])
+	}
+
+	return m.Leaves() - 1
+}
+
+// Truncates the range to the amount of leaves.
+func (m *MMR) Truncate(n int) {
+	for h := range m.levels {
+		if c := n >> h; c < len(m.levels[h]) {
+			m.levels[h] = m.levels[h][:c]
+		}
+	}
+}
+
+// Copies the range, so the copy can change without changing the range.
+func (m MMR) Clone() *MMR {
+	c := &MMR{levels: make([][][]byte, len(m.levels))}
+	for h, l := range m.levels {
+		c.levels[h] = append([][]byte(nil), l...)
+	}
+
+	return c
+}
+
+// Gets the peaks, highest first.
+// A node is a peak when it has not been merged, which is the last node of a height with an odd count.
+func (m MMR) Peaks() (peaks [][]byte) {
+	for h := len(m.levels) - 1; h >= 0; h-- {
+		if l := len(m.levels[h]); l%2 == 1 {
+			peaks = append(peaks, m.levels[h][l-1])
+		}
+	}
+
+	return
+}
+
+// Gets the root, the bagged peaks.
+// Will be nil for an empty range.
+func (m MMR) Root() []byte {
+	return bag(m.Peaks())
+}
+
+// Proves the leaf at the index is in the range.
+// If no leaf is found, error is returned.
+func (m MMR) Prove(i int) (*Proof, error) {
+	if i < 0 || i >= m.Leaves() {
+		return nil, errors.New("no leaf found")
+	}
+
+	p := &Proof{Index: i, Peaks: m.Peaks()}
+
+	// Climb while the node has been merged into a parent.
+	h := 0
+	for ; h+1 < len(m.levels) && i>>(h+1) < len(m.levels[h+1]); h++ {
+		p.Siblings = append(p.Siblings, m.levels[h][(i>>h)^1])
+	}
+
+	// Peaks higher than this one come first.
+	for ph := len(m.levels) - 1; ph > h; ph-- {
+		if len(m.levels[ph])%2 == 1 {
+			p.Peak++
+		}
+	}
+
+	return p, nil
+}
+
+// Verifies the proof shows the data is a leaf of the range with the root.
+func Verify(root []byte, data []byte, p *Proof) bool {
+	if p == nil || p.Peak < 0 || p.Peak >= len(p.Peaks) {
+		return false
+	}
+
+	n := hashLeaf(data)
+	for h, s := range p.Siblings {
+		if (p.Index>>h)&1 == 0 {
+			n = hashNode(n, s)
+		} else {
+			n = hashNode(s, n)
+		}
+	}
+
+	return bytes.Equal(n, p.Peaks[p.Peak]) && bytes.Equal(bag(p.Peaks), root)
+}
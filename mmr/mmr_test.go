@@ -0,0 +1,87 @@
+package mmr
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+// Test clones change independently of their range.
+func TestClone(t *testing.T) {
+	m := New()
+	for i := 0; i < 5; i++ {
+		m.Append([]byte(strconv.Itoa(i)))
+	}
+
+	root := m.Root()
+	c := m.Clone()
+	c.Truncate(3)
+	c.Append([]byte("x"))
+
+	if !bytes.Equal(m.Root(), root) || m.Leaves() != 5 || c.Leaves() != 4 {
+		t.Errorf("expected the range to be unchanged by its clone")
+	}
+}
+
+// Test every leaf can be proven for ranges of many sizes.
+func TestProve(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		m := New()
+		for i := 0; i < n; i++ {
+			m.Append([]byte(strconv.Itoa(i)))
+		}
+
+		root := m.Root()
+		for i := 0; i < n; i++ {
+			p, err := m.Prove(i)
+			if err != nil {
+				t.Fatalf("expected proof of leaf %d of %d but got %s", i, n, err)
+			}
+
+			if !Verify(root, []byte(strconv.Itoa(i)), p) {
+				t.Errorf("expected proof of leaf %d of %d to verify", i, n)
+			}
+
+			if Verify(root, []byte("other"), p) {
+				t.Errorf("expected proof of leaf %d of %d to fail for other data", i, n)
+			}
+		}
+	}
+}
+
+// Test the peaks follow the binary representation of the leaf count.
+func TestPeaks(t *testing.T) {
+	m := New()
+	if m.Root() != nil {
+		t.Errorf("expected empty range to have no root")
+	}
+
+	for i := 0; i < 11; i++ {
+		m.Append([]byte{byte(i)})
+	}
+
+	// 11 is 8 + 2 + 1.
+	if l := len(m.Peaks()); l != 3 {
+		t.Errorf("expected 3 peaks but got %d", l)
+	}
+
+	if _, err := m.Prove(11); err == nil {
+		t.Errorf("expected proof of missing leaf to return error")
+	}
+}
+
+// Test truncating gives the same root as a range built to that size.
+func TestTruncate(t *testing.T) {
+	m, e := New(), New()
+	for i := 0; i < 13; i++ {
+		m.Append([]byte{byte(i)})
+		if i < 6 {
+			e.Append([]byte{byte(i)})
+		}
+	}
+
+	m.Truncate(6)
+	if m.Leaves() != 6 || !bytes.Equal(m.Root(), e.Root()) {
+		t.Errorf("expected truncated root to match")
+	}
+}
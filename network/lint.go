@@ -0,0 +1,69 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Highest difficulty a PoW can solve, one "0" for each hex character of a SHA256.
+const MaxDifficulty = 64
+
+// Checks the network for inconsistent parameters, without stopping at the first problem.
+// Run it before launching a network, as its parameters can not change once blocks are mined.
+func (n Network) Lint() (errs []error) {
+	if n.Name == "" {
+		errs = append(errs, errors.New("network has no name"))
+	}
+
+	if n.ID < 0 {
+		errs = append(errs, fmt.Errorf("chain ID %d is negative", n.ID))
+	}
+
+	errs = append(errs, lintParams("genesis", n.Difficulty, n.MinInterval)...)
+
+	seen := make(map[int]bool)
+	for _, u := range n.Upgrades {
+		at := fmt.Sprintf("upgrade at height %d", u.Height)
+		if u.Height < 1 {
+			errs = append(errs, fmt.Errorf("%s replaces the genesis parameters", at))
+		}
+
+		if seen[u.Height] {
+			errs = append(errs, fmt.Errorf("%s is scheduled more than once", at))
+		}
+		seen[u.Height] = true
+
+		errs = append(errs, lintParams(at, u.Difficulty, u.MinInterval)...)
+
+		// Dropping to 0 after launch lets anyone seal blocks without PoW.
+		if u.Difficulty == 0 && n.DifficultyAt(u.Height-1) > 0 {
+			errs = append(errs, fmt.Errorf("%s disables PoW", at))
+		}
+	}
+
+	return
+}
+
+// Checks the network for inconsistent parameters.
+// If any are found, the first is returned as error.
+func (n Network) Validate() error {
+	if errs := n.Lint(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// Checks the consensus parameters of the genesis or an upgrade.
+func lintParams(at string, dif int, min time.Duration) (errs []error) {
+	if dif < 0 || dif > MaxDifficulty {
+		errs = append(errs, fmt.Errorf("%s has difficulty %d outside 0 to %d", at, dif, MaxDifficulty))
+	}
+
+	if min < 0 {
+		errs = append(errs, fmt.Errorf("%s has negative minimum interval", at))
+	}
+
+	return
+}
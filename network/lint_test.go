@@ -0,0 +1,46 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+// Test the presets have consistent parameters.
+func TestPresetsLint(t *testing.T) {
+	for _, n := range Presets {
+		if err := n.Validate(); err != nil {
+			t.Errorf("expected network %s to be valid but got %s", n.Name, err)
+		}
+	}
+}
+
+// Test every problem of a network is found.
+func TestLint(t *testing.T) {
+	n := Network{
+		ID:          -1,
+		Difficulty:  65,
+		MinInterval: -time.Second,
+		Upgrades: []Upgrade{
+			{Height: 0, Difficulty: 2},
+			{Height: 10, Difficulty: 2},
+			{Height: 10, Difficulty: 3},
+			{Height: 20, Difficulty: 0},
+		},
+	}
+
+	// No name, negative ID, genesis difficulty and interval, upgrade at 0, duplicate height, and PoW disabled.
+	if errs := n.Lint(); len(errs) != 7 {
+		t.Errorf("expected 7 problems but got %d: %v", len(errs), errs)
+	}
+
+	if n.Validate() == nil {
+		t.Errorf("expected invalid network to return error")
+	}
+
+	// Upgrades to a difficulty of 0 are fine when PoW was never enabled.
+	d := Dev
+	d.Upgrades = []Upgrade{{Height: 10, Difficulty: 0}}
+	if err := d.Validate(); err != nil {
+		t.Errorf("expected dev network to be valid but got %s", err)
+	}
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"errors"
+	"time"
+
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/jcs"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+type (
+	// Reprecents the parameters of a network.
+	Network struct {
+		Name        string        `json:"name"`
+		ID          int           `json:"id"`
+		Difficulty  int           `json:"difficulty"`
+		MinInterval time.Duration `json:"min_interval"`
+		Upgrades    []Upgrade     `json:"upgrades,omitempty"` // Scheduled parameter changes.
+	}
+
+	// Reprecents a change of the network's parameters, from a height onwards.
+	// Every parameter is replaced, so unchanged parameters must be repeated.
+	Upgrade struct {
+		Height      int           `json:"height"`
+		Difficulty  int           `json:"difficulty"`
+		MinInterval time.Duration `json:"min_interval"`
+	}
+)
+
+// Built-in network presets.
+var (
+	Dev     = Network{Name: "dev", ID: 1337, Difficulty: 0}
+	Testnet = Network{Name: "testnet", ID: 2, Difficulty: 2, MinInterval: time.Second}
+	Mainnet = Network{Name: "mainnet", ID: 1, Difficulty: 4, MinInterval: 10 * time.Second}
+)
+
+// All built-in network presets.
+var Presets = []Network{Dev, Testnet, Mainnet}
+
+// Gets a built-in network preset by name, suitable for a "--network" flag.
+// If no preset is found, error is returned.
+func Lookup(name string) (Network, error) {
+	for _, n := range Presets {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+
+	return Network{}, errors.New("no network found")
+}
+
+// Creates a new chain for the network.
+// The chain enforces the network's parameters, including upgrades, when validating.
+func (n Network) Chain() *chain.Chain {
+	c := chain.New()
+	c.ID = n.ID
+	c.MinInterval = n.MinInterval
+	c.Spec = n
+
+	return c
+}
+
+// Gets the parameters of the network at the height, with the latest upgrade at or below it applied.
+func (n Network) At(h int) Network {
+	var at *Upgrade
+	for i, u := range n.Upgrades {
+		if u.Height <= h && (at == nil || u.Height > at.Height) {
+			at = &n.Upgrades[i]
+		}
+	}
+
+	if at != nil {
+		n.Difficulty, n.MinInterval = at.Difficulty, at.MinInterval
+	}
+
+	return n
+}
+
+// Gets the difficulty of the network at the height.
+func (n Network) DifficultyAt(h int) int {
+	return n.At(h).Difficulty
+}
+
+// Gets the minimum time between blocks of the network at the height.
+func (n Network) MinIntervalAt(h int) time.Duration {
+	return n.At(h).MinInterval
+}
+
+// Creates the genesis block for the network.
+func (n Network) Genesis(data string) *miner.Block {
+	blk := miner.New(nil, n.DifficultyAt(0), data)
+	if ck, ok := blk.Miner.(*miner.Chunk); ok {
+		ck.ChainID = n.ID
+	}
+
+	return blk
+}
+
+// Creates a new block based on a previous block with the network's difficulty at the block's height.
+// If the previous block does not hold a chunk, error is returned.
+func (n Network) New(blk *miner.Block, data string) (*miner.Block, error) {
+	var pm miner.Miner
+	if blk != nil {
+		pm = blk.Miner
+	}
+
+	return miner.NewWith(blk, miner.NewChunk, n.NextDifficulty(pm), data)
+}
+
+// Hashes the chain spec: the genesis hash, chain ID, and consensus parameters.
+// Nodes can exchange this hash to detect an incompatible peer before receiving blocks from it.
+// The spec is hashed as canonical JSON (RFC 8785), so other implementations can reproduce the hash.
+func (n Network) SpecHash(genesis []byte) []byte {
+	j, _ := json.Marshal(struct {
+		Genesis     []byte        `json:"genesis"`
+		ID          int           `json:"id"`
+		Difficulty  int           `json:"difficulty"`
+		MinInterval time.Duration `json:"min_interval"`
+		Upgrades    []Upgrade     `json:"upgrades,omitempty"`
+	}{
+		Genesis:     genesis,
+		ID:          n.ID,
+		Difficulty:  n.Difficulty,
+		MinInterval: n.MinInterval,
+		Upgrades:    n.Upgrades,
+	})
+
+	// The encoding is valid JSON without NaN or infinity, so it always canonicalizes.
+	j, _ = jcs.Transform(j)
+	sum := sha256.Sum256(j)
+
+	return sum[:]
+}
+
+// Gives the network's difficulty for the block after the parent, so the network can be used as a difficulty engine.
+func (n Network) NextDifficulty(parent miner.Miner) int {
+	if parent == nil {
+		return n.DifficultyAt(0)
+	}
+
+	return n.DifficultyAt(parent.GetIndex() + 1)
+}
@@ -0,0 +1,150 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"crypto/sha256"
+
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Test presets can be looked up by name.
+func TestLookup(t *testing.T) {
+	for _, e := range Presets {
+		a, err := Lookup(e.Name)
+		if err != nil || !reflect.DeepEqual(a, e) {
+			t.Errorf("expected to find network %s", e.Name)
+		}
+	}
+
+	if _, err := Lookup("unknown"); err == nil {
+		t.Errorf("expected unknown network to return error")
+	}
+}
+
+// Test blocks created for a network are accepted by its chain.
+func TestNetworkChain(t *testing.T) {
+	n := Dev
+	c := n.Chain()
+
+	blk := n.Genesis("One")
+	blk.Mine()
+	blk.GenerateHash(true)
+
+	blk2, err := n.New(blk, "Two")
+	if err != nil {
+		t.Fatalf("expected block to be created but got %s", err)
+	}
+	blk2.Mine()
+	blk2.GenerateHash(true)
+
+	if err := c.Append(true, blk); err != nil {
+		t.Errorf("expected genesis block to be appended but got %s", err)
+	}
+
+	if err := c.Append(true, blk2); err != nil {
+		t.Errorf("expected block to be appended but got %s", err)
+	}
+
+	if err := Testnet.Chain().Append(false, blk); err == nil {
+		t.Errorf("expected block from another network to be rejected")
+	}
+}
+
+// Miner which is not a chunk.
+type customMiner struct {
+	*miner.Chunk
+}
+
+// Test blocks after a block which does not hold a chunk return an error instead of panicking.
+func TestNetworkNewNotChunk(t *testing.T) {
+	prev := &miner.Block{Miner: customMiner{Chunk: &miner.Chunk{}}}
+	if _, err := Dev.New(prev, "Two"); !errors.Is(err, chainerr.ErrInvalidMiner) {
+		t.Errorf("expected invalid miner error but got %v", err)
+	}
+}
+
+// Test spec hashes differ for incompatible networks.
+func TestSpecHash(t *testing.T) {
+	g := []byte("genesis")
+
+	if !bytes.Equal(Dev.SpecHash(g), Dev.SpecHash(g)) {
+		t.Errorf("expected spec hash to be reproducible")
+	}
+
+	if bytes.Equal(Dev.SpecHash(g), Testnet.SpecHash(g)) {
+		t.Errorf("expected networks to have different spec hashes")
+	}
+
+	if bytes.Equal(Dev.SpecHash(g), Dev.SpecHash([]byte("other"))) {
+		t.Errorf("expected genesis blocks to have different spec hashes")
+	}
+
+	// Hash of the canonical JSON, with sorted keys, which other implementations can reproduce.
+	n := Testnet
+	n.Upgrades = []Upgrade{{Height: 10, Difficulty: 3, MinInterval: time.Second}}
+	e := sha256.Sum256([]byte(`{"difficulty":2,"genesis":"Z2VuZXNpcw==","id":2,"min_interval":1000000000,` +
+		`"upgrades":[{"difficulty":3,"height":10,"min_interval":1000000000}]}`))
+	if a := n.SpecHash(g); !bytes.Equal(a, e[:]) {
+		t.Errorf("expected spec hash to be %x but got %x", e, a)
+	}
+}
+
+// Test upgrades change the parameters from their height onwards.
+func TestUpgrades(t *testing.T) {
+	n := Dev
+	n.Upgrades = []Upgrade{
+		{Height: 4, Difficulty: 2},
+		{Height: 2, Difficulty: 1, MinInterval: time.Millisecond},
+	}
+
+	for h, e := range []int{0, 0, 1, 1, 2, 2} {
+		if a := n.DifficultyAt(h); a != e {
+			t.Errorf("expected a difficulty of %d at height %d but got %d", e, h, a)
+		}
+	}
+
+	if n.MinIntervalAt(3) != time.Millisecond || n.MinIntervalAt(4) != 0 {
+		t.Errorf("expected upgrades to replace the min interval")
+	}
+
+	if bytes.Equal(n.SpecHash(nil), Dev.SpecHash(nil)) {
+		t.Errorf("expected upgrades to change the spec hash")
+	}
+}
+
+// Test the chain enforces the difficulty scheduled for each height.
+func TestUpgradesEnforced(t *testing.T) {
+	n := Dev
+	n.Upgrades = []Upgrade{{Height: 2, Difficulty: 1}}
+	c := n.Chain()
+
+	blk := n.Genesis("Zero")
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			blk, _ = n.New(blk, "Block")
+		}
+		blk.Mine()
+		blk.GenerateHash(true)
+
+		if err := c.Append(true, blk); err != nil {
+			t.Fatalf("expected block %d to be appended but got %s", i, err)
+		}
+	}
+
+	if d := blk.GetDifficulty(); d != 1 {
+		t.Errorf("expected the upgraded difficulty of 1 but got %d", d)
+	}
+
+	// Block which ignores the upgrade.
+	bad := miner.New(blk, 0, "Old rules")
+	bad.GenerateHash(true)
+	if err := c.Append(true, bad); !errors.Is(err, chainerr.ErrBadDifficulty) {
+		t.Errorf("expected bad difficulty error but got %v", err)
+	}
+}
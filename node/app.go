@@ -0,0 +1,62 @@
+package node
+
+import (
+	"errors"
+)
+
+// Application which defines the state transitions of the chain's data.
+// The controller handles ordering, mining, and storing the data, the application only handles its meaning.
+// Methods are called from the mining loop one at a time, except CheckTx and Query which may be called at any time.
+type Application interface {
+	// Checks the data can be applied, before it is queued for mining.
+	CheckTx(data string) error
+
+	// Applies the data of a block once it is in the chain.
+	DeliverTx(data string) error
+
+	// Commits the state after a block was applied, returning the hash of the state.
+	Commit() []byte
+
+	// Queries the state of the application.
+	Query(path string, data []byte) ([]byte, error)
+}
+
+// Queries the state of the application.
+// Will return error if the controller has no application.
+func (mc *MinerController) Query(path string, data []byte) ([]byte, error) {
+	if mc.App == nil {
+		return nil, errors.New("miner has no application")
+	}
+
+	return mc.App.Query(path, data)
+}
+
+// Gets the hash of the application's state, as of the last block applied.
+func (mc *MinerController) AppHash() []byte {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.appHash
+}
+
+// Applies the block's data to the application and commits the state.
+// Empty blocks have no data to deliver, but are still committed.
+func (mc *MinerController) deliver(data string) error {
+	if mc.App == nil {
+		return nil
+	}
+
+	if data != "" {
+		if err := mc.App.DeliverTx(data); err != nil {
+			return err
+		}
+	}
+
+	h := mc.App.Commit()
+
+	mc.mu.Lock()
+	mc.appHash = h
+	mc.mu.Unlock()
+
+	return nil
+}
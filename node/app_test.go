@@ -0,0 +1,111 @@
+package node
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ohmybrew/gochain/chain"
+)
+
+// Application which keeps a list of the data delivered.
+type listApp struct {
+	mu        sync.Mutex
+	delivered []string
+	commits   int
+}
+
+func (a *listApp) CheckTx(data string) error {
+	if data == "bad" {
+		return errors.New("bad data")
+	}
+
+	return nil
+}
+
+func (a *listApp) DeliverTx(data string) error {
+	if data == "fail" {
+		return errors.New("can not apply")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.delivered = append(a.delivered, data)
+
+	return nil
+}
+
+func (a *listApp) Commit() []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.commits++
+
+	return []byte(strings.Join(a.delivered, ","))
+}
+
+func (a *listApp) Query(path string, data []byte) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return []byte(strconv.Itoa(len(a.delivered))), nil
+}
+
+// Test mined data is checked, delivered, and committed to the application.
+func TestApplication(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(2)
+	app := new(listApp)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.App = app
+
+	if err := mc.Submit("bad"); err == nil || mc.Pending() != 0 {
+		t.Errorf("expected data rejected by the application to not be queued")
+	}
+
+	mc.Start()
+	mc.Submit("One")
+	mc.Submit("Two")
+	for i := 0; i < 2; i++ {
+		<-ch
+	}
+	mc.Stop()
+
+	if string(mc.AppHash()) != "One,Two" || app.commits != 2 {
+		t.Errorf("expected both blocks to be delivered and committed but got %s", mc.AppHash())
+	}
+
+	if q, err := mc.Query("count", nil); err != nil || string(q) != "2" {
+		t.Errorf("expected query to reach the application but got %s", q)
+	}
+}
+
+// Test the miner stops if the application fails to apply data.
+func TestApplicationDeliverError(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(1)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.App = new(listApp)
+
+	mc.Start()
+	mc.Submit("fail")
+	<-ch
+
+	// Wait for the miner to stop itself.
+	mc.Stop()
+	if mc.Err() == nil {
+		t.Errorf("expected miner to stop with an error")
+	}
+}
+
+// Test querying without an application.
+func TestQueryWithoutApplication(t *testing.T) {
+	if _, err := NewMinerController(chain.New(), 1).Query("count", nil); err == nil {
+		t.Errorf("expected error without an application")
+	}
+}
@@ -0,0 +1,386 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/clock"
+	"github.com/ohmybrew/gochain/miner"
+)
+
+// Returned when the miner is stopped while waiting to mine a block.
+var errStopped = errors.New("miner is stopped")
+
+// Controls a miner which mines pending data into blocks and appends them to the chain.
+// While running, the chain should only be modified by the controller.
+type MinerController struct {
+	Chain      *chain.Chain
+	Difficulty int           // Difficulty of new blocks, unless the chain has a spec.
+	Factory    miner.Factory // Factory for the miner of new blocks, miner.NewChunk if nil.
+	Instamine  bool          // Only mine a block when data is pending, otherwise empty blocks are mined.
+	EmptyAfter time.Duration // When instamining, mine an empty block if no data is pending for this long. Never if zero.
+	App        Application   // Application the mined data is delivered to, if any.
+	Clock      clock.Clock   // Time source for chunk timestamps, minimum intervals, and empty block deadlines, the system clock if nil.
+
+	mu      sync.Mutex
+	pending []string      // Data waiting to be mined.
+	running bool          // Miner is started.
+	paused  bool          // Miner is paused.
+	err     error         // Error which stopped the miner.
+	wake    chan struct{} // Signals the miner to check for work.
+	quit    chan struct{} // Signals the miner to stop.
+	done    chan struct{} // Closed once the miner has stopped.
+	appHash []byte        // Hash of the application's state.
+}
+
+// Creates a new miner controller for the chain.
+func NewMinerController(c *chain.Chain, dif int) *MinerController {
+	return &MinerController{
+		Chain:      c,
+		Difficulty: dif,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Starts the miner.
+// Will return error if already running.
+func (mc *MinerController) Start() error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.running {
+		return errors.New("miner is already running")
+	}
+
+	mc.running, mc.paused, mc.err = true, false, nil
+	mc.quit, mc.done = make(chan struct{}), make(chan struct{})
+	go mc.loop(mc.quit, mc.done)
+
+	return nil
+}
+
+// Stops the miner, waiting for the block being mined to finish.
+func (mc *MinerController) Stop() {
+	mc.mu.Lock()
+	if !mc.running {
+		mc.mu.Unlock()
+		return
+	}
+
+	mc.running = false
+	close(mc.quit)
+	done := mc.done
+	mc.mu.Unlock()
+
+	<-done
+}
+
+// Pauses the miner after the block being mined.
+func (mc *MinerController) Pause() {
+	mc.mu.Lock()
+	mc.paused = true
+	mc.mu.Unlock()
+}
+
+// Resumes a paused miner.
+func (mc *MinerController) Resume() {
+	mc.mu.Lock()
+	mc.paused = false
+	mc.mu.Unlock()
+
+	mc.signal()
+}
+
+// Determines if the miner is running and not paused.
+func (mc *MinerController) IsMining() bool {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.running && !mc.paused
+}
+
+// Gets the error which stopped the miner, if any.
+func (mc *MinerController) Err() error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.err
+}
+
+// Submits data to be mined into a block.
+// Will return error if the application rejects the data.
+func (mc *MinerController) Submit(data string) error {
+	if mc.App != nil {
+		if err := mc.App.CheckTx(data); err != nil {
+			return fmt.Errorf("can not submit data, %w", err)
+		}
+	}
+
+	mc.mu.Lock()
+	mc.pending = append(mc.pending, data)
+	mc.mu.Unlock()
+
+	mc.signal()
+
+	return nil
+}
+
+// Gets the amount of data waiting to be mined.
+func (mc *MinerController) Pending() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return len(mc.pending)
+}
+
+// Wakes the miner without blocking.
+func (mc *MinerController) signal() {
+	select {
+	case mc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Gets the next data to mine, and if it was taken from the pending data.
+// Returns false if there is no work to do.
+func (mc *MinerController) next() (data string, queued, ok bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.paused {
+		return "", false, false
+	}
+
+	if len(mc.pending) > 0 {
+		data := mc.pending[0]
+		mc.pending = mc.pending[1:]
+
+		return data, true, true
+	}
+
+	// Nothing pending, mine an empty block unless instamining.
+	return "", false, !mc.Instamine
+}
+
+// Puts data back at the front of the pending data, after it failed to be mined.
+func (mc *MinerController) requeue(data string) {
+	mc.mu.Lock()
+	mc.pending = append([]string{data}, mc.pending...)
+	mc.mu.Unlock()
+}
+
+// Mining loop, runs until quit is closed or a block fails to append.
+func (mc *MinerController) loop(quit, done chan struct{}) {
+	defer close(done)
+
+	last := mc.now() // Time of the last mined block.
+	for {
+		select {
+		case <-quit:
+			return
+		default:
+		}
+
+		data, queued, ok := mc.next()
+		if !ok {
+			// No work, wait to be woken or for the empty block deadline on the clock.
+			var t clock.Timer
+			var deadline <-chan time.Time
+			if d, empty := mc.emptyDeadline(last); empty {
+				t = mc.clock().NewTimer(d)
+				deadline = t.C()
+			}
+
+			woke := false
+			select {
+			case <-quit:
+				woke = true
+			case <-mc.wake:
+				woke = true
+			case <-deadline:
+				// Deadline passed with no data, mine an empty block.
+			}
+
+			if t != nil {
+				t.Stop()
+			}
+			if woke {
+				continue
+			}
+		}
+
+		if err := mc.mine(quit, data, queued); err != nil {
+			if errors.Is(err, errStopped) {
+				return
+			}
+
+			mc.mu.Lock()
+			mc.running, mc.err = false, err
+			mc.mu.Unlock()
+
+			return
+		}
+
+		last = mc.now()
+	}
+}
+
+// Gets the time left until an empty block should be mined.
+// Returns false if empty blocks should not be mined.
+func (mc *MinerController) emptyDeadline(last time.Time) (time.Duration, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.paused || !mc.Instamine || mc.EmptyAfter <= 0 {
+		return 0, false
+	}
+
+	return last.Add(mc.EmptyAfter).Sub(mc.now()), true
+}
+
+// Gets the clock of the controller, the system clock if it has none.
+func (mc *MinerController) clock() clock.Clock {
+	if mc.Clock == nil {
+		return clock.System
+	}
+
+	return mc.Clock
+}
+
+// Gets the current time from the clock.
+func (mc *MinerController) now() time.Time {
+	return mc.clock().Now()
+}
+
+// Waits until the chain's minimum interval after the previous block has passed, so the block is not rejected.
+// Returns errStopped if the miner was stopped first.
+func (mc *MinerController) waitInterval(quit chan struct{}, prev *miner.Block) error {
+	d := prev.GetTimestamp().Add(mc.minInterval(prev.GetIndex() + 1)).Sub(mc.now())
+	if d <= 0 {
+		return nil
+	}
+
+	t := mc.clock().NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-quit:
+		return errStopped
+	case <-t.C():
+		return nil
+	}
+}
+
+// Mines the block until its PoW is found, or the miner is stopped.
+// Chunks which give up at the max attempts of their options try again with the next extra nonce,
+// as the same extra nonce would try the same PoW values again.
+func (mc *MinerController) seal(quit chan struct{}, blk *miner.Block) error {
+	for {
+		if _, ok := blk.Mine(); ok {
+			return nil
+		}
+
+		// Other miners and nonce sources can not be retried.
+		ck, ok := blk.Miner.(*miner.Chunk)
+		if !ok || ck.GetOptions().Nonces != nil {
+			return fmt.Errorf("can not mine block, %w", chainerr.ErrPoWNotFound)
+		}
+
+		select {
+		case <-quit:
+			return errStopped
+		default:
+		}
+
+		ck.ExtraNonce++
+	}
+}
+
+// Gets the minimum time between blocks of the chain at the height, from its spec if it has one.
+func (mc *MinerController) minInterval(h int) time.Duration {
+	if mc.Chain.Spec != nil {
+		return mc.Chain.Spec.MinIntervalAt(h)
+	}
+
+	return mc.Chain.MinInterval
+}
+
+// Gets the difficulty of a block at the height, from the chain's spec if it has one.
+// Following the spec lets scheduled upgrades apply without restarting the miner.
+func (mc *MinerController) difficulty(h int) int {
+	if mc.Chain.Spec != nil {
+		return mc.Chain.Spec.DifficultyAt(h)
+	}
+
+	return mc.Difficulty
+}
+
+// Mines the data into a block, appends it to the chain, and delivers it to the application.
+// If the block fails to be mined or appended, data taken from the pending data is put back.
+// If the application fails to apply the data, the miner stops as the state can not be trusted.
+func (mc *MinerController) mine(quit chan struct{}, data string, queued bool) (err error) {
+	appended := false
+	defer func() {
+		if err != nil && queued && !appended {
+			mc.requeue(data)
+		}
+	}()
+
+	f := mc.Factory
+	if f == nil {
+		f = miner.NewChunk
+	}
+
+	h := 0
+	prev, err := mc.Chain.Last()
+	if err != nil {
+		prev = nil // Empty chain, mine the genesis block.
+	} else if err := mc.waitInterval(quit, prev); err != nil {
+		return err
+	} else {
+		h = prev.GetIndex() + 1
+	}
+
+	blk, err := miner.NewWith(prev, f, mc.difficulty(h), data)
+	if err != nil {
+		return fmt.Errorf("can not create block, %w", err)
+	}
+
+	if ck, ok := blk.Miner.(*miner.Chunk); ok {
+		// Genesis chunks take the chain's ID.
+		if prev == nil {
+			ck.ChainID = mc.Chain.ID
+		}
+
+		if mc.Clock != nil {
+			ck.Timestamp = mc.now().UnixMilli()
+		}
+
+		// Never timestamp before the minimum interval, such as when the clock is behind it.
+		if prev != nil {
+			if min := prev.GetTimestamp().Add(mc.minInterval(h)).UnixMilli(); ck.Timestamp < min {
+				ck.Timestamp = min
+			}
+		}
+	}
+
+	if err := mc.seal(quit, blk); err != nil {
+		return err
+	}
+	blk.GenerateHash(true)
+
+	if err := mc.Chain.Append(true, blk); err != nil {
+		return err
+	}
+	appended = true
+
+	if err := mc.deliver(data); err != nil {
+		return fmt.Errorf("can not deliver data, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ohmybrew/gochain/chain"
+	"github.com/ohmybrew/gochain/chainerr"
+	"github.com/ohmybrew/gochain/clock"
+	"github.com/ohmybrew/gochain/miner"
+	"github.com/ohmybrew/gochain/network"
+)
+
+// Test instamine only mines when data is submitted.
+func TestInstamine(t *testing.T) {
+	c := chain.New()
+	c.ID = 5
+	ch := c.Subscribe(1)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	if err := mc.Start(); err != nil {
+		t.Fatalf("expected miner to start but got %s", err)
+	}
+
+	if err := mc.Start(); err == nil {
+		t.Errorf("expected second start to fail")
+	}
+
+	mc.Submit("One")
+	mc.Submit("Two")
+	for i := 0; i < 2; i++ {
+		<-ch
+	}
+	mc.Stop()
+
+	if c.Length() != 2 || !c.IsValid() {
+		t.Errorf("expected chain to have 2 valid blocks, got %d", c.Length())
+	}
+
+	if c.Blocks[0].GetChainID() != 5 {
+		t.Errorf("expected genesis block to take the chain ID")
+	}
+
+	if mc.IsMining() || mc.Err() != nil {
+		t.Errorf("expected miner to be stopped cleanly")
+	}
+}
+
+// Test a paused miner holds pending data until resumed.
+func TestPauseResume(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(1)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Start()
+	mc.Pause()
+
+	mc.Submit("One")
+	if mc.IsMining() || mc.Pending() != 1 {
+		t.Errorf("expected paused miner to hold pending data")
+	}
+
+	mc.Resume()
+	<-ch
+	mc.Stop()
+
+	if mc.Pending() != 0 || c.Length() != 1 {
+		t.Errorf("expected resumed miner to mine pending data")
+	}
+}
+
+// Test continuous mining produces empty blocks.
+func TestContinuousMining(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(0)
+
+	mc := NewMinerController(c, 1)
+	mc.Start()
+	for i := 0; i < 3; i++ {
+		<-ch
+	}
+
+	// Stop must not block on the subscriber, keep receiving.
+	go func() {
+		for range ch {
+		}
+	}()
+	mc.Stop()
+	c.Unsubscribe(ch)
+
+	if c.Length() < 3 || !c.IsValid() {
+		t.Errorf("expected at least 3 valid blocks, got %d", c.Length())
+	}
+}
+
+// Test the miner keeps mining valid blocks with the default settings.
+func TestContinuousMiningLong(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(0)
+
+	mc := NewMinerController(c, 1)
+	mc.Start()
+	for i := 0; i < 24; i++ {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected block %d to be mined but got %v", i, mc.Err())
+		}
+	}
+
+	go func() {
+		for range ch {
+		}
+	}()
+	mc.Stop()
+	c.Unsubscribe(ch)
+
+	if mc.Err() != nil || !c.IsValid() {
+		t.Errorf("expected a valid chain but got %v", mc.Err())
+	}
+}
+
+// Test blocks are not mined before the chain's minimum interval.
+func TestMinInterval(t *testing.T) {
+	c := chain.New()
+	c.MinInterval = 20 * time.Millisecond
+	ch := c.Subscribe(3)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Start()
+
+	for _, d := range []string{"One", "Two", "Three"} {
+		mc.Submit(d)
+	}
+	for i := 0; i < 3; i++ {
+		<-ch
+	}
+	mc.Stop()
+
+	if mc.Err() != nil || c.Length() != 3 {
+		t.Errorf("expected 3 blocks but got %d and %v", c.Length(), mc.Err())
+	}
+}
+
+// Test the miner follows the difficulty scheduled by the chain's spec.
+func TestSpecUpgrades(t *testing.T) {
+	n := network.Network{Name: "upgrade", ID: 9, Difficulty: 1, Upgrades: []network.Upgrade{{Height: 2, Difficulty: 2}}}
+	c := n.Chain()
+	ch := c.Subscribe(4)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Start()
+
+	for _, d := range []string{"One", "Two", "Three", "Four"} {
+		mc.Submit(d)
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected block %d to be mined but got %v", i, mc.Err())
+		}
+	}
+	mc.Stop()
+
+	for i, e := range []int{1, 1, 2, 2} {
+		if a := c.Blocks[i].GetDifficulty(); a != e {
+			t.Errorf("expected difficulty of %d at height %d but got %d", e, i, a)
+		}
+	}
+}
+
+// Test blocks which reach the max attempts are mined again instead of appended unmined.
+func TestMaxAttempts(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(3)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Factory = miner.NewOptions(miner.WithMaxAttempts(2)).Factory()
+	mc.Start()
+
+	for _, d := range []string{"One", "Two", "Three"} {
+		mc.Submit(d)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected block %d to be mined but got %v", i, mc.Err())
+		}
+	}
+	mc.Stop()
+
+	if mc.Err() != nil || !c.IsValid() || c.Blocks[0].Miner.(*miner.Chunk).ExtraNonce == 0 {
+		t.Errorf("expected valid blocks mined with an extra nonce but got %v", mc.Err())
+	}
+}
+
+// Test nonce sources which run out leave the data pending.
+func TestNonceSourceExhausted(t *testing.T) {
+	c := chain.New()
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Factory = miner.NewOptions(miner.WithNonceSource(miner.Sequence(1, 2))).Factory()
+	mc.Start()
+	mc.Submit("One")
+
+	for i := 0; mc.Err() == nil; i++ {
+		if i == 100 {
+			t.Fatalf("expected miner to stop")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mc.Stop()
+
+	if !errors.Is(mc.Err(), chainerr.ErrPoWNotFound) || mc.Pending() != 1 || c.Length() != 0 {
+		t.Errorf("expected PoW not found with data pending but got %v", mc.Err())
+	}
+}
+
+// Test data is put back when its block fails to be created.
+func TestRequeue(t *testing.T) {
+	c := chain.New()
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Factory = func(parent miner.Miner, dif int, data string) (miner.Miner, error) {
+		return nil, errors.New("factory failed")
+	}
+	mc.Start()
+	mc.Submit("One")
+
+	for i := 0; mc.Err() == nil; i++ {
+		if i == 100 {
+			t.Fatalf("expected miner to stop")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mc.Stop()
+
+	if mc.Pending() != 1 || c.Length() != 0 {
+		t.Errorf("expected data to be put back, got %d pending", mc.Pending())
+	}
+}
+
+// Test instamine mines an empty block once the deadline passes with no data.
+func TestEmptyAfter(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(1)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.EmptyAfter = 10 * time.Millisecond
+	mc.Start()
+
+	select {
+	case e := <-ch:
+		if (e.Block.Miner).(*miner.Chunk).Data != "" {
+			t.Errorf("expected an empty block to be mined")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected an empty block to be mined after the deadline")
+	}
+
+	mc.Pause()
+	go func() {
+		for range ch {
+		}
+	}()
+	mc.Stop()
+	c.Unsubscribe(ch)
+}
+
+// Test empty block deadlines follow the clock, not the system time.
+func TestEmptyAfterClock(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(1)
+	clk := clock.NewManual(time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC))
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.EmptyAfter = 10 * time.Millisecond
+	mc.Clock = clk
+	mc.Start()
+
+	// Frozen clock, the deadline never passes.
+	time.Sleep(100 * time.Millisecond)
+	if c.Length() != 0 {
+		t.Errorf("expected no blocks while the clock is frozen but got %d", c.Length())
+	}
+
+	mined := false
+	for i := 0; i < 100 && !mined; i++ {
+		clk.Advance(time.Hour)
+		select {
+		case <-ch:
+			mined = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if !mined {
+		t.Errorf("expected an empty block once the clock passed the deadline")
+	}
+
+	mc.Pause()
+	go func() {
+		for range ch {
+		}
+	}()
+	mc.Stop()
+	c.Unsubscribe(ch)
+}
+
+// Test mined blocks are timestamped with the clock.
+func TestClock(t *testing.T) {
+	c := chain.New()
+	ch := c.Subscribe(1)
+	ts := time.Date(2019, 3, 24, 13, 42, 58, 0, time.UTC)
+
+	mc := NewMinerController(c, 1)
+	mc.Instamine = true
+	mc.Clock = clock.NewManual(ts)
+	mc.Start()
+
+	mc.Submit("One")
+	e := <-ch
+	mc.Stop()
+
+	if a := e.Block.GetTimestamp(); !a.Equal(ts) {
+		t.Errorf("expected timestamp of %s but got %s", ts, a)
+	}
+}
@@ -0,0 +1,67 @@
+package version
+
+import (
+	"encoding/hex"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build details, set at build time with -ldflags, for example:
+//
+//	go build -trimpath -ldflags "-X github.com/ohmybrew/gochain/version.Version=v1.0.0"
+//
+// If the commit or date are not set, they are read from the VCS details Go embeds in the binary.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Reprecents the version of a build, for operators to check they run identical code.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes.
+	GoVersion string `json:"go_version"`
+	SpecHash  string `json:"spec_hash,omitempty"` // Hash of the chain spec the build runs, see network.SpecHash.
+}
+
+// Gets the version of the build with the chain spec hash.
+// The spec hash can be nil if the build does not run a chain.
+func Get(spec []byte) Info {
+	i := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if spec != nil {
+		i.SpecHash = hex.EncodeToString(spec)
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		i.fill(bi.Settings)
+	}
+
+	return i
+}
+
+// Fills the commit and date from the VCS build settings, if not set already.
+func (i *Info) fill(settings []debug.BuildSetting) {
+	for _, s := range settings {
+		switch s.Key {
+		case "vcs.revision":
+			if i.Commit == "" {
+				i.Commit = s.Value
+			}
+		case "vcs.time":
+			if i.Date == "" {
+				i.Date = s.Value
+			}
+		case "vcs.modified":
+			i.Modified = s.Value == "true"
+		}
+	}
+}
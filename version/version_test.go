@@ -0,0 +1,48 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+// Test the version includes the build details and spec hash.
+func TestGet(t *testing.T) {
+	Version, Commit, Date = "v1.0.0", "abc123", "2019-03-24T00:00:00Z"
+	defer func() { Version, Commit, Date = "dev", "", "" }()
+
+	i := Get([]byte{0xde, 0xad})
+	if i.Version != "v1.0.0" || i.Commit != "abc123" || i.Date != "2019-03-24T00:00:00Z" {
+		t.Errorf("expected build details from the variables but got %+v", i)
+	}
+
+	if i.SpecHash != "dead" || i.GoVersion != runtime.Version() {
+		t.Errorf("expected spec hash and Go version but got %+v", i)
+	}
+
+	if i := Get(nil); i.SpecHash != "" {
+		t.Errorf("expected no spec hash but got %s", i.SpecHash)
+	}
+}
+
+// Test the commit and date fall back to the VCS build settings.
+func TestFill(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "abc123"},
+		{Key: "vcs.time", Value: "2019-03-24T00:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	var i Info
+	i.fill(settings)
+	if i.Commit != "abc123" || i.Date != "2019-03-24T00:00:00Z" || !i.Modified {
+		t.Errorf("expected build details from the VCS settings but got %+v", i)
+	}
+
+	// Variables win over the VCS settings.
+	i = Info{Commit: "def456"}
+	i.fill(settings)
+	if i.Commit != "def456" {
+		t.Errorf("expected commit to be kept but got %s", i.Commit)
+	}
+}